@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// imageAggregator groups notifications by image within a window so a bad image rollout produces
+// one alert instead of one per container.
+type imageAggregator struct {
+	mu     sync.Mutex
+	groups map[string][]notification
+}
+
+var imageGroups = imageAggregator{groups: make(map[string][]notification)}
+
+// add collects the notification under its image and opens the image's window if needed.
+func (a *imageAggregator) add(n notification, cfg *Config) {
+	image := n.event.Actor.Attributes["image"]
+
+	a.mu.Lock()
+	_, open := a.groups[image]
+	a.groups[image] = append(a.groups[image], n)
+	a.mu.Unlock()
+
+	if !open {
+		time.AfterFunc(time.Duration(cfg.ImageAggregateSeconds)*time.Second, func() {
+			sender.submit(func() { a.flush(image, currentConfig(cfg)) })
+		})
+	}
+}
+
+// flush sends the image's notifications once its window closes: a single one as usual, several
+// as one summary at the most severe level among them.
+func (a *imageAggregator) flush(image string, cfg *Config) {
+	a.mu.Lock()
+	group := a.groups[image]
+	delete(a.groups, image)
+	a.mu.Unlock()
+
+	if len(group) == 0 {
+		return
+	}
+	if len(group) == 1 {
+		notify(group[0], cfg)
+		return
+	}
+
+	level := group[0].level
+	actions := make(map[string][]string)
+	for _, n := range group {
+		if levelRank(n.level) > levelRank(level) {
+			level = n.level
+		}
+		name := n.event.Actor.Attributes["name"]
+		actions[name] = append(actions[name], string(n.event.Action))
+	}
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	header := fmt.Sprintf("**Image**: `%s`\n**Affected containers**: %d (%d events)", image, len(names), len(group))
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("`%s`: %s", name, strings.Join(actions[name], ", ")))
+	}
+	title := fmt.Sprintf("Docker Image Events - %s", strings.ToUpper(level))
+	slog.Info("Aggregated image events", "events", len(group), "image", image)
+	if err := sendPaged(cfg, webhookFor(level, cfg), summaryPages(title, header, lines, level, cfg)); err != nil {
+		slog.Error("Failed to send image summary", "error", err)
+		return
+	}
+	slog.Info("Successfully sent image summary")
+}
+
+// flushAll sends every open image group without waiting for its window, used on shutdown.
+func (a *imageAggregator) flushAll(cfg *Config) {
+	a.mu.Lock()
+	images := make([]string, 0, len(a.groups))
+	for image := range a.groups {
+		images = append(images, image)
+	}
+	a.mu.Unlock()
+
+	for _, image := range images {
+		a.flush(image, cfg)
+	}
+}
+
+// levelRank orders the built-in levels by severity. Custom levels rank below them.
+func levelRank(level string) int {
+	switch level {
+	case "error":
+		return 3
+	case "warning":
+		return 2
+	case "info":
+		return 1
+	default:
+		return 0
+	}
+}
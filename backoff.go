@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff defaults used when the corresponding config values are unset.
+const (
+	defaultBackoffBase        = time.Second
+	defaultBackoffMax         = 30 * time.Second
+	defaultBackoffMaxAttempts = 4
+)
+
+// BackoffConfig selects how retry delays grow for reconnects and webhook retries.
+type BackoffConfig struct {
+	// Strategy is "constant", "linear" or "exponential" (default).
+	Strategy string `json:"strategy,omitempty"`
+	BaseMs   int    `json:"base_ms,omitempty"`
+	MaxMs    int    `json:"max_ms,omitempty"`
+	// MaxAttempts caps webhook delivery attempts, including the first one (default 4).
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Jitter randomizes each delay by up to this fraction in either direction, e.g. 0.2 for ±20%.
+	Jitter float64 `json:"jitter,omitempty"`
+}
+
+// validate rejects unknown strategies and negative durations.
+func (b BackoffConfig) validate() error {
+	switch b.Strategy {
+	case "", "constant", "linear", "exponential":
+	default:
+		return fmt.Errorf("invalid backoff strategy %q: must be constant, linear or exponential", b.Strategy)
+	}
+	if b.BaseMs < 0 || b.MaxMs < 0 || b.MaxAttempts < 0 {
+		return fmt.Errorf("backoff base_ms, max_ms and max_attempts must not be negative")
+	}
+	if b.Jitter < 0 || b.Jitter > 1 {
+		return fmt.Errorf("backoff jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// maxAttempts returns the configured number of delivery attempts.
+func (b BackoffConfig) maxAttempts() int {
+	if b.MaxAttempts <= 0 {
+		return defaultBackoffMaxAttempts
+	}
+	return b.MaxAttempts
+}
+
+// wait returns the delay before retry number attempt with jitter applied.
+func (b BackoffConfig) wait(attempt int) time.Duration {
+	d := b.delay(attempt)
+	if b.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + b.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+// delay returns how long to wait before retry number attempt, starting at 1, capped at the maximum.
+func (b BackoffConfig) delay(attempt int) time.Duration {
+	base, limit := defaultBackoffBase, defaultBackoffMax
+	if b.BaseMs > 0 {
+		base = time.Duration(b.BaseMs) * time.Millisecond
+	}
+	if b.MaxMs > 0 {
+		limit = time.Duration(b.MaxMs) * time.Millisecond
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	d := base
+	switch b.Strategy {
+	case "constant":
+	case "linear":
+		d = base * time.Duration(attempt)
+	default:
+		for i := 1; i < attempt && d < limit; i++ {
+			d *= 2
+		}
+	}
+	if d > limit || d <= 0 {
+		return limit
+	}
+	return d
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		durations := make([]time.Duration, len(values))
+		for i, v := range values {
+			durations[i] = time.Duration(v) * time.Millisecond
+		}
+		return durations
+	}
+	tests := []struct {
+		name    string
+		backoff BackoffConfig
+		want    []time.Duration
+	}{
+		{"constant", BackoffConfig{Strategy: "constant", BaseMs: 100, MaxMs: 1000}, ms(100, 100, 100, 100, 100)},
+		{"linear", BackoffConfig{Strategy: "linear", BaseMs: 100, MaxMs: 350}, ms(100, 200, 300, 350, 350)},
+		{"exponential", BackoffConfig{Strategy: "exponential", BaseMs: 100, MaxMs: 1000}, ms(100, 200, 400, 800, 1000)},
+		{"exponential by default", BackoffConfig{BaseMs: 100, MaxMs: 300}, ms(100, 200, 300, 300, 300)},
+		{"defaults", BackoffConfig{}, ms(1000, 2000, 4000, 8000, 16000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []time.Duration
+			for attempt := 1; attempt <= len(tt.want); attempt++ {
+				got = append(got, tt.backoff.delay(attempt))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("delays = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelayCapsLongSequences(t *testing.T) {
+	b := BackoffConfig{BaseMs: 1000, MaxMs: 30000}
+	if got := b.delay(100); got != 30*time.Second {
+		t.Fatalf("delay(100) = %v, want the 30s cap", got)
+	}
+}
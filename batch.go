@@ -0,0 +1,113 @@
+package main
+
+import (
+	"log"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// embedBatcher collects embeds for a short window and sends them together, up to Discord's limit
+// of embeds per message, to avoid a message per event during deployments.
+type embedBatcher struct {
+	mu      sync.Mutex
+	cfg     *Config
+	pending map[string]*pendingBatch
+}
+
+// pendingBatch holds the embeds waiting for one webhook until the earliest of their windows ends.
+type pendingBatch struct {
+	embeds   []map[string]interface{}
+	deadline time.Time
+	timer    *time.Timer
+}
+
+var batch = embedBatcher{pending: make(map[string]*pendingBatch)}
+
+// batchWindow returns how long notifications of the level wait for others to batch with:
+// the level's entry in BatchWindowsMs, otherwise BatchWindowMs.
+func batchWindow(level string, cfg *Config) time.Duration {
+	ms, ok := cfg.BatchWindowsMs[level]
+	if !ok {
+		ms = cfg.BatchWindowMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// add queues the embed for the webhook. A full batch is sent right away; otherwise the batch is
+// sent when the shortest window of its embeds, each starting when the embed was added, elapses.
+func (b *embedBatcher) add(cfg *Config, webhookURL string, embed map[string]interface{}, window time.Duration) {
+	b.mu.Lock()
+	b.cfg = cfg
+	p := b.pending[webhookURL]
+	if p == nil {
+		p = &pendingBatch{}
+		b.pending[webhookURL] = p
+	}
+	p.embeds = append(p.embeds, embed)
+	var full []map[string]interface{}
+	if len(p.embeds) >= messageEmbedLimit {
+		full = b.takeLocked(webhookURL)
+	} else if deadline := time.Now().Add(window); p.timer == nil || deadline.Before(p.deadline) {
+		if p.timer != nil {
+			p.timer.Stop()
+		}
+		p.deadline = deadline
+		p.timer = time.AfterFunc(window, func() {
+			sender.submit(func() { b.flushWebhook(webhookURL) })
+		})
+	}
+	b.mu.Unlock()
+
+	if full != nil {
+		sendBatch(cfg, webhookURL, full)
+	}
+}
+
+// takeLocked removes the webhook's pending batch and returns its embeds. b.mu must be held.
+func (b *embedBatcher) takeLocked(webhookURL string) []map[string]interface{} {
+	p := b.pending[webhookURL]
+	if p == nil {
+		return nil
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	delete(b.pending, webhookURL)
+	return p.embeds
+}
+
+// flushWebhook sends the webhook's pending batch once its window has elapsed.
+func (b *embedBatcher) flushWebhook(webhookURL string) {
+	b.mu.Lock()
+	embeds, cfg := b.takeLocked(webhookURL), b.cfg
+	b.mu.Unlock()
+
+	if len(embeds) > 0 {
+		sendBatch(cfg, webhookURL, embeds)
+	}
+}
+
+// flush sends every pending batch. It is also called on shutdown so nothing is left behind.
+func (b *embedBatcher) flush() {
+	b.mu.Lock()
+	cfg := b.cfg
+	pending := make(map[string][]map[string]interface{}, len(b.pending))
+	for webhookURL := range b.pending {
+		pending[webhookURL] = b.takeLocked(webhookURL)
+	}
+	b.mu.Unlock()
+
+	for webhookURL, embeds := range pending {
+		sendBatch(cfg, webhookURL, embeds)
+	}
+}
+
+// sendBatch sends the embeds in as few messages as the size limits allow.
+func sendBatch(cfg *Config, webhookURL string, embeds []map[string]interface{}) {
+	if err := sendPaged(cfg, webhookURL, embeds); err != nil {
+		slog.Error("Failed to send batch", "notifications", len(embeds), "error", err)
+		return
+	}
+	log.Printf("Successfully sent batch of %d notification(s)", len(embeds))
+}
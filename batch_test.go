@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEmbedBatcherSendsAtShortestWindow(t *testing.T) {
+	t.Cleanup(resetAppContext)
+	received := make(chan int, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Embeds []json.RawMessage `json:"embeds"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received <- len(payload.Embeds)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Webhook: srv.URL}
+	b := embedBatcher{pending: make(map[string]*pendingBatch)}
+	go sender.run(appCtx)
+	b.add(cfg, srv.URL, newEmbed("info", "", "info", cfg), time.Minute)
+	b.add(cfg, srv.URL, newEmbed("warning", "", "warning", cfg), 50*time.Millisecond)
+
+	select {
+	case n := <-received:
+		if n != 2 {
+			t.Fatalf("batch held %d embeds, want both", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch was not sent when the shorter window elapsed")
+	}
+	drain(cfg, nil)
+}
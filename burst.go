@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"log"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultErrorBurstWindow is used when error bursts are enabled without an explicit window.
+const defaultErrorBurstWindow = 60 * time.Second
+
+// errorBurst switches error notifications to a periodic summary while error volume is high.
+type errorBurst struct {
+	mu         sync.Mutex
+	recent     []time.Time
+	active     bool
+	count      int
+	containers map[string]int
+}
+
+var burst errorBurst
+
+// errorBurstWindow returns the configured burst window.
+func errorBurstWindow(cfg *Config) time.Duration {
+	if cfg.ErrorBurstWindowSeconds <= 0 {
+		return defaultErrorBurstWindow
+	}
+	return time.Duration(cfg.ErrorBurstWindowSeconds) * time.Second
+}
+
+// record registers an error event and reports whether it is held back for the burst summary.
+func (b *errorBurst) record(event events.Message, cfg *Config, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	window := errorBurstWindow(cfg)
+	b.recent = append(pruneBefore(b.recent, now.Add(-window)), now)
+	if !b.active && len(b.recent) > cfg.ErrorBurstThreshold {
+		slog.Warn("Error burst detected, switching to summaries", "errors", len(b.recent), "window", window)
+		b.active = true
+		b.containers = make(map[string]int)
+		time.AfterFunc(window, func() { sender.submit(func() { b.flush(currentConfig(cfg)) }) })
+	}
+	if !b.active {
+		return false
+	}
+
+	b.count++
+	b.containers[event.Actor.Attributes["name"]]++
+	return true
+}
+
+// flush sends the summary for the held errors and leaves burst mode once volume has subsided.
+func (b *errorBurst) flush(cfg *Config) {
+	now := time.Now()
+	window := errorBurstWindow(cfg)
+
+	b.mu.Lock()
+	count, containers := b.count, b.containers
+	b.count, b.containers = 0, make(map[string]int)
+	b.recent = pruneBefore(b.recent, now.Add(-window))
+	if len(b.recent) > cfg.ErrorBurstThreshold {
+		time.AfterFunc(window, func() { sender.submit(func() { b.flush(currentConfig(cfg)) }) })
+	} else {
+		b.active = false
+		log.Println("Error burst subsided, resuming individual notifications")
+	}
+	b.mu.Unlock()
+
+	if count == 0 {
+		return
+	}
+	header, lines := burstSummary(count, containers, window)
+	embeds := summaryPages("Docker Error Burst - ERROR", header, lines, "error", cfg)
+	if err := sendPaged(cfg, webhookFor("error", cfg), embeds); err != nil {
+		slog.Error("Failed to send error burst summary", "error", err)
+		return
+	}
+	log.Println("Successfully sent error burst summary")
+}
+
+// burstSummary summarizes held errors, listing the noisiest containers first.
+func burstSummary(count int, containers map[string]int, window time.Duration) (string, []string) {
+	names := make([]string, 0, len(containers))
+	for name := range containers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if containers[names[i]] != containers[names[j]] {
+			return containers[names[i]] > containers[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	header := fmt.Sprintf("**%d errors** across **%d containers** in the last %s", count, len(containers), window)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("`%s`: %d", name, containers[name]))
+	}
+	return header, lines
+}
+
+// pruneBefore drops the leading timestamps older than cutoff from a chronologically ordered slice.
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
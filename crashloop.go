@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// defaultCrashLoopWindow is used when crash-loop detection is enabled without an explicit window.
+const defaultCrashLoopWindow = 5 * time.Minute
+
+// crashLoopDetector counts container deaths from the event stream to spot crash loops.
+type crashLoopDetector struct {
+	mu         sync.Mutex
+	containers map[string]*crashLoopState
+}
+
+type crashLoopState struct {
+	dies    []time.Time
+	looping bool
+}
+
+var crashLoops = crashLoopDetector{containers: make(map[string]*crashLoopState)}
+
+// crashLoopWindow returns the configured crash-loop window.
+func crashLoopWindow(cfg *Config) time.Duration {
+	if cfg.CrashLoopWindowSeconds <= 0 {
+		return defaultCrashLoopWindow
+	}
+	return time.Duration(cfg.CrashLoopWindowSeconds) * time.Second
+}
+
+// observe tracks start and die events and reports whether the event should be suppressed because
+// its container is crash looping. The crash-loop alert is sent once when the threshold is exceeded.
+func (d *crashLoopDetector) observe(event events.Message, cfg *Config, now time.Time) bool {
+	if event.Action != events.ActionStart && event.Action != events.ActionDie {
+		return false
+	}
+	window := crashLoopWindow(cfg)
+	cutoff := now.Add(-window)
+
+	d.mu.Lock()
+	for id, state := range d.containers {
+		if state.dies = pruneBefore(state.dies, cutoff); len(state.dies) == 0 {
+			if state.looping {
+				slog.Info("Container stabilized, resuming notifications", "id", id)
+			}
+			delete(d.containers, id)
+		}
+	}
+	state, ok := d.containers[event.Actor.ID]
+	if !ok {
+		state = &crashLoopState{}
+		d.containers[event.Actor.ID] = state
+	}
+	if event.Action == events.ActionDie {
+		state.dies = append(state.dies, now)
+	}
+	alert := !state.looping && len(state.dies) > cfg.CrashLoopRestarts
+	if alert {
+		state.looping = true
+	}
+	looping, count := state.looping, len(state.dies)
+	d.mu.Unlock()
+
+	if alert {
+		sender.submit(func() { sendCrashLoopAlert(event, count, window, cfg) })
+	}
+	return looping
+}
+
+// sendCrashLoopAlert notifies that a container restarted count times within the window.
+func sendCrashLoopAlert(event events.Message, count int, window time.Duration, cfg *Config) {
+	name := event.Actor.Attributes["name"]
+	slog.Warn("Crash loop detected", "container", name, "restarts", count)
+	description := fmt.Sprintf("**Container**: `%s`\n**Restarts**: %d within %s\nFurther start/die alerts are suppressed until it stabilizes.", name, count, window)
+	embed := newEmbed("Container Crash Loop - ERROR", description, "error", cfg)
+	if err := sendEmbeds(cfg, webhookFor("error", cfg), embed); err != nil {
+		slog.Error("Failed to send crash loop alert", "container", name, "error", err)
+		return
+	}
+	slog.Info("Successfully sent crash loop alert")
+}
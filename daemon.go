@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultDaemonWait bounds how long startup waits for the Docker daemon when docker_wait_seconds
+// is unset.
+const defaultDaemonWait = 60 * time.Second
+
+// daemonPinger is the part of the Docker client used to check that the daemon is reachable.
+type daemonPinger interface {
+	Ping(ctx context.Context) (types.Ping, error)
+	DaemonHost() string
+}
+
+// daemonWait returns how long startup waits for each Docker daemon.
+func daemonWait(cfg *Config) time.Duration {
+	if cfg.DockerWaitSeconds <= 0 {
+		return defaultDaemonWait
+	}
+	return time.Duration(cfg.DockerWaitSeconds) * time.Second
+}
+
+// waitForDaemon pings the daemon until it answers, retrying with the configured backoff until the
+// wait period is over. The returned error explains the most likely cause.
+func waitForDaemon(ctx context.Context, name string, cli daemonPinger, cfg *Config) error {
+	deadline := time.Now().Add(daemonWait(cfg))
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, preflightTimeout)
+		_, err := cli.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Docker daemon%s reachable after %d attempts", hostSuffix(name), attempt)
+			}
+			return nil
+		}
+
+		delay := cfg.Backoff.wait(attempt)
+		remaining := time.Until(deadline)
+		if remaining <= 0 || ctx.Err() != nil {
+			return fmt.Errorf("Docker daemon%s at %s is not reachable after %d attempts: %v (%s)", hostSuffix(name), cli.DaemonHost(), attempt, err, daemonHint(cli.DaemonHost(), err))
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+		slog.Warn("Docker daemon not reachable, retrying", hostAttrs(name, "attempt", attempt, "remaining", remaining.Round(time.Second), "delay", delay, "error", err)...)
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+}
+
+// waitForDaemons waits for the daemons of all hosts in parallel. Startup fails only when none of
+// them is reachable; with several hosts the unreachable ones are logged and left to the event
+// stream, which keeps reconnecting.
+func waitForDaemons(ctx context.Context, hosts []DockerHostConfig, clients []daemonPinger, cfg *Config) error {
+	errs := make([]error, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = waitForDaemon(ctx, host.Name, clients[i], cfg)
+		}()
+	}
+	wg.Wait()
+
+	if !slices.Contains(errs, nil) {
+		return errors.Join(errs...)
+	}
+	for _, err := range errs {
+		if err != nil {
+			slog.Error("Starting without an unreachable Docker host", "error", err)
+		}
+	}
+	return nil
+}
+
+// daemonHint names the likely setup mistake behind a failed ping. The client reports a missing
+// socket as a generic connection failure, so a local socket is looked up directly.
+func daemonHint(host string, err error) string {
+	msg := err.Error()
+	socket, isSocket := strings.CutPrefix(host, "unix://")
+	if isSocket {
+		if _, statErr := os.Stat(socket); os.IsNotExist(statErr) {
+			return fmt.Sprintf("%s does not exist; mount the Docker socket into the container or set DOCKER_HOST", socket)
+		}
+	}
+	switch {
+	case errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EACCES) || strings.Contains(msg, "permission denied"):
+		return "the Docker socket is not accessible; check its permissions or add the user to the docker group"
+	case client.IsErrConnectionFailed(err) || errors.Is(err, syscall.ECONNREFUSED):
+		return "the Docker daemon is not running or DOCKER_HOST points to the wrong address"
+	default:
+		return "check that the Docker daemon is running and DOCKER_HOST is correct"
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/docker/docker/api/types"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePinger fails the first failures pings and answers every later one.
+type fakePinger struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (f *fakePinger) Ping(ctx context.Context) (types.Ping, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failures {
+		return types.Ping{}, errors.New("dial unix /var/run/docker.sock: connect: permission denied")
+	}
+	return types.Ping{}, nil
+}
+
+func (f *fakePinger) DaemonHost() string {
+	return "tcp://127.0.0.1:2375"
+}
+
+// fastRetries retries pings every 50ms for at most a second.
+var fastRetries = &Config{Backoff: BackoffConfig{Strategy: "constant", BaseMs: 50}, DockerWaitSeconds: 1}
+
+func TestWaitForDaemonRetriesUntilReachable(t *testing.T) {
+	cli := &fakePinger{failures: 3}
+	if err := waitForDaemon(context.Background(), "", cli, fastRetries); err != nil {
+		t.Fatalf("waitForDaemon() error = %v, want nil", err)
+	}
+	if cli.calls != 4 {
+		t.Fatalf("Ping called %d times, want 4", cli.calls)
+	}
+}
+
+func TestWaitForDaemonGivesUpWithHint(t *testing.T) {
+	err := waitForDaemon(context.Background(), "", &fakePinger{failures: 1 << 30}, fastRetries)
+	if err == nil {
+		t.Fatal("waitForDaemon() error = nil, want an error for an unreachable daemon")
+	}
+	if want := "check its permissions"; !strings.Contains(err.Error(), want) {
+		t.Fatalf("waitForDaemon() error = %q, want it to mention %q", err, want)
+	}
+}
+
+func TestWaitForDaemonsStartsWithReachableHosts(t *testing.T) {
+	hosts := []DockerHostConfig{{Name: "up"}, {Name: "down"}}
+	clients := []daemonPinger{&fakePinger{failures: 2}, &fakePinger{failures: 1 << 30}}
+	if err := waitForDaemons(context.Background(), hosts, clients, fastRetries); err != nil {
+		t.Fatalf("waitForDaemons() error = %v, want nil while one host is reachable", err)
+	}
+
+	clients = []daemonPinger{&fakePinger{failures: 1 << 30}, &fakePinger{failures: 1 << 30}}
+	if err := waitForDaemons(context.Background(), hosts, clients, fastRetries); err == nil {
+		t.Fatal("waitForDaemons() error = nil, want an error when no host is reachable")
+	}
+}
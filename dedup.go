@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDedupWindow is used when deduplication is enabled without an explicit window.
+const defaultDedupWindow = time.Minute
+
+// deduplicator counts notifications per resource and action within a fixed window.
+type deduplicator struct {
+	mu      sync.Mutex
+	windows map[string]*dedupWindow
+}
+
+type dedupWindow struct {
+	// group is the rendered DedupKeyTemplate, empty when windows are kept per container and action.
+	group      string
+	host       string
+	name       string
+	action     string
+	level      string
+	window     time.Duration
+	count      int
+	suppressed int
+}
+
+var dedup = deduplicator{windows: make(map[string]*dedupWindow)}
+
+// dedupWindowDuration returns the configured deduplication window.
+func dedupWindowDuration(cfg *Config) time.Duration {
+	if cfg.DedupWindowSeconds <= 0 {
+		return defaultDedupWindow
+	}
+	return time.Duration(cfg.DedupWindowSeconds) * time.Second
+}
+
+// record counts the notification and reports whether it is a duplicate to suppress. The first
+// notification of a key, by default the resource and action, opens a window; once it closes, a
+// summary is sent if anything was suppressed and the entry is removed, so the tracker only holds
+// open windows.
+func (d *deduplicator) record(n notification, cfg *Config) bool {
+	name, action := resourceName(n.event), actionKey(n.event)
+	key := n.host + "\x00" + name + "\x00" + action
+	var group string
+	if cfg.dedupKey != nil {
+		group = renderTemplate(cfg.dedupKey, n, n.receivedAt, key)
+		key = "template\x00" + group
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w, ok := d.windows[key]
+	if !ok {
+		w = &dedupWindow{group: group, host: n.host, name: name, action: action, level: n.level, window: dedupWindowDuration(cfg)}
+		d.windows[key] = w
+		time.AfterFunc(w.window, func() {
+			sender.submit(func() { d.close(key, currentConfig(cfg)) })
+		})
+	}
+	w.count++
+	if w.count <= cfg.DedupThreshold {
+		return false
+	}
+	w.suppressed++
+	return true
+}
+
+// close removes the window and sends a summary when duplicates were suppressed.
+func (d *deduplicator) close(key string, cfg *Config) {
+	d.mu.Lock()
+	w := d.windows[key]
+	delete(d.windows, key)
+	d.mu.Unlock()
+
+	if w == nil || w.suppressed == 0 {
+		return
+	}
+	// A templated key may group several containers, so the summary names the key instead.
+	subject := fmt.Sprintf("**Container**: `%s`\n**Action**: `%s`", w.name, w.action)
+	if w.group != "" {
+		subject = fmt.Sprintf("**Duplicates of**: `%s`", w.group)
+		slog.Info("Deduplicated events", "suppressed", w.suppressed, "key", w.group)
+	} else {
+		slog.Info("Deduplicated events", "suppressed", w.suppressed, "container", w.name, "action", w.action)
+	}
+	description := fmt.Sprintf("%s\n**Occurrences**: %d within %s\n**Suppressed**: %d", subject, w.count, w.window, w.suppressed)
+	if w.host != "" && w.group == "" {
+		description += fmt.Sprintf("\n**Docker Host**: `%s`", w.host)
+	}
+	embed := newEmbed(fmt.Sprintf("Docker Event Summary - %s", strings.ToUpper(w.level)), description, w.level, cfg)
+	if err := sendEmbeds(cfg, webhookFor(w.level, cfg), embed); err != nil {
+		slog.Error("Failed to send dedup summary", "error", err)
+		return
+	}
+	slog.Info("Successfully sent dedup summary")
+}
+
+// closeAll closes every open window, sending the pending summaries, used on shutdown.
+func (d *deduplicator) closeAll(cfg *Config) {
+	d.mu.Lock()
+	keys := make([]string, 0, len(d.windows))
+	for key := range d.windows {
+		keys = append(keys, key)
+	}
+	d.mu.Unlock()
+
+	for _, key := range keys {
+		d.close(key, cfg)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"testing"
+	"time"
+)
+
+func TestDeduplicatorKeyTemplate(t *testing.T) {
+	event := func(name string, image string) notification {
+		return notification{
+			event:      events.Message{Type: events.ContainerEventType, Action: "die", Actor: events.Actor{Attributes: map[string]string{"name": name, "image": image}}},
+			level:      "error",
+			receivedAt: time.Now(),
+		}
+	}
+	tests := []struct {
+		name     string
+		template string
+		want     []bool
+	}{
+		{"per container by default", "", []bool{false, false, true}},
+		{"per image", "{{.Image}} {{.Action}}", []bool{false, true, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{DedupThreshold: 1, DedupWindowSeconds: 60, DedupKeyTemplate: tt.template}
+			if err := compileTemplates(cfg); err != nil {
+				t.Fatal(err)
+			}
+			d := deduplicator{windows: make(map[string]*dedupWindow)}
+			for i, n := range []notification{event("web-1", "nginx"), event("web-2", "nginx"), event("web-1", "nginx")} {
+				if got := d.record(n, cfg); got != tt.want[i] {
+					t.Fatalf("record() #%d = %v, want %v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
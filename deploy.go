@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"sync"
+	"time"
+)
+
+// defaultDeployLabel is the container label that opens a deployment window when set to "true".
+const defaultDeployLabel = "dockacord.deploy"
+
+// deployWindow tracks how long non-error notifications are suppressed for an ongoing deployment.
+type deployWindow struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+var deployment deployWindow
+
+// observe opens or extends the window if the event carries the deploy label.
+func (d *deployWindow) observe(event events.Message, cfg *Config, now time.Time) {
+	label := cfg.DeployLabel
+	if label == "" {
+		label = defaultDeployLabel
+	}
+	if event.Actor.Attributes[label] != "true" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	until := now.Add(time.Duration(cfg.DeployWindowSeconds) * time.Second)
+	if until.After(d.until) {
+		d.until = until
+	}
+}
+
+// active reports whether a deployment window is currently open.
+func (d *deployWindow) active(now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return now.Before(d.until)
+}
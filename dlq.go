@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// defaultDeadLetterMax bounds the dead-letter file when no explicit size is configured.
+const defaultDeadLetterMax = 100
+
+// deadLetterQueue stores undeliverable notifications in a JSON-lines file and replays them once
+// delivery works again.
+type deadLetterQueue struct {
+	mu       sync.Mutex
+	draining bool
+}
+
+var dlq deadLetterQueue
+
+// push appends the payload to the dead-letter file, dropping the oldest entries beyond the limit.
+func (q *deadLetterQueue) push(cfg *Config, webhookURL string, payload interface{}) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to marshal dead letter", "error", err)
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	letters, err := readMessageFile(cfg.DeadLetterPath)
+	if err != nil {
+		slog.Error("Failed to read dead-letter file", "error", err)
+		return
+	}
+	letters = append(letters, queuedMessage{ID: nextMessageID(letters), Webhook: webhookURL, Payload: raw})
+	limit := cfg.DeadLetterMax
+	if limit <= 0 {
+		limit = defaultDeadLetterMax
+	}
+	if dropped := len(letters) - limit; dropped > 0 {
+		notificationsSuppressed.add("dead_letter_queue_full", dropped)
+		slog.Warn("Dead-letter queue full, dropping oldest notifications", "dropped", dropped)
+		letters = letters[dropped:]
+	}
+	if err := writeMessageFile(cfg.DeadLetterPath, letters); err != nil {
+		slog.Error("Failed to write dead-letter file", "error", err)
+		return
+	}
+	slog.Warn("Stored undeliverable notification in dead-letter queue", "pending", len(letters))
+}
+
+// drain replays queued notifications in order, stopping at the first one that still fails for a
+// transient reason. Letters the webhook rejects are dropped. Only one drain runs at a time.
+func (q *deadLetterQueue) drain(cfg *Config) {
+	q.mu.Lock()
+	if q.draining {
+		q.mu.Unlock()
+		return
+	}
+	q.draining = true
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		q.draining = false
+		q.mu.Unlock()
+	}()
+
+	for {
+		q.mu.Lock()
+		letters, err := readMessageFile(cfg.DeadLetterPath)
+		q.mu.Unlock()
+		if err != nil {
+			slog.Error("Failed to read dead-letter file", "error", err)
+			return
+		}
+		if len(letters) == 0 {
+			return
+		}
+
+		sent := letters[0]
+		_, err = postWebhook(cfg, sent.Webhook, sent.Payload)
+		if err != nil && retryableWebhookError(err) {
+			slog.Warn("Dead-letter replay failed", "pending", len(letters), "error", err)
+			return
+		}
+		// A letter the webhook rejects outright would block the others forever, so it is dropped.
+		if err != nil {
+			slog.Error("Dropping dead letter rejected by webhook", "error", err)
+		}
+
+		// Re-read under the lock so entries pushed during the replay are kept, and remove the
+		// letter by ID as a push may have trimmed it meanwhile.
+		q.mu.Lock()
+		letters, err = readMessageFile(cfg.DeadLetterPath)
+		if err == nil {
+			err = writeMessageFile(cfg.DeadLetterPath, withoutMessage(letters, sent))
+		}
+		q.mu.Unlock()
+		if err != nil {
+			slog.Error("Failed to update dead-letter file", "error", err)
+			return
+		}
+		if err == nil {
+			slog.Info("Replayed notification from dead-letter queue")
+		}
+	}
+}
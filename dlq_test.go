@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeadLetterDrainKeepsLettersTrimmedDuringReplay(t *testing.T) {
+	cfg := &Config{DeadLetterMax: 2}
+	cfg.DeadLetterPath = filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	q := &deadLetterQueue{}
+
+	pushed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// While the first letter is replayed, a new one arrives and trims it from the full file.
+		if !pushed {
+			pushed = true
+			q.push(cfg, "http://127.0.0.1:1/c", map[string]string{"content": "c"})
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	q.push(cfg, srv.URL, map[string]string{"content": "a"})
+	q.push(cfg, "http://127.0.0.1:1/b", map[string]string{"content": "b"})
+	q.drain(cfg)
+
+	letters, err := readMessageFile(cfg.DeadLetterPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 2 || letters[0].Webhook != "http://127.0.0.1:1/b" || letters[1].Webhook != "http://127.0.0.1:1/c" {
+		t.Fatalf("letters after drain = %+v, want b and c", letters)
+	}
+}
+
+func TestDeadLetterDrainDropsRejectedLetters(t *testing.T) {
+	cfg := &Config{DeadLetterPath: filepath.Join(t.TempDir(), "dead-letters.jsonl")}
+	q := &deadLetterQueue{}
+
+	delivered := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/deleted" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delivered = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	q.push(cfg, srv.URL+"/deleted", map[string]string{"content": "a"})
+	q.push(cfg, srv.URL+"/live", map[string]string{"content": "b"})
+	q.drain(cfg)
+
+	if !delivered {
+		t.Fatal("the letter behind a rejected one was not replayed")
+	}
+	letters, err := readMessageFile(cfg.DeadLetterPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 0 {
+		t.Fatalf("letters after drain = %+v, want none", letters)
+	}
+}
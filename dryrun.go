@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// dryRunFlag is set by -dry-run and forces DryRun on every loaded config, including reloads.
+var dryRunFlag bool
+
+// printPayload writes the payload that would be posted to the webhook as indented JSON to stdout.
+func printPayload(webhookURL string, payload interface{}) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(payload); err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+	_, err := fmt.Fprintf(os.Stdout, "Dry run, would post to %s:\n%s", redactWebhook(webhookURL), b.Bytes())
+	return err
+}
+
+// redactWebhook hides the token, the last path segment of a Discord webhook URL, so dry-run output
+// can be shared.
+func redactWebhook(webhookURL string) string {
+	if webhookURL == "" {
+		return "<no webhook>"
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "<invalid webhook URL>"
+	}
+	redacted := u.Path
+	if i := strings.LastIndex(redacted, "/"); i >= 0 && i < len(redacted)-1 {
+		redacted = redacted[:i+1] + "***"
+	}
+	return u.Scheme + "://" + u.Host + redacted
+}
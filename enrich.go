@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Enrichment defaults.
+const (
+	defaultEnrichCacheTTL = 5 * time.Second
+	enrichTimeout         = 5 * time.Second
+)
+
+// containerInspector is the part of the Docker client used to enrich notifications.
+type containerInspector interface {
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+}
+
+// inspector is set in main once the Docker client has been created.
+var inspector containerInspector
+
+// inspectCache keeps inspect results briefly so bursts of events for one container inspect it once.
+type inspectCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedInspect
+}
+
+type cachedInspect struct {
+	resp container.InspectResponse
+	at   time.Time
+}
+
+var inspections = inspectCache{entries: make(map[string]cachedInspect)}
+
+// embedField is a Discord embed field.
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// shouldEnrich reports whether the action is listed in EnrichActions. Actions with a detail suffix
+// such as "health_status: unhealthy" also match their base name.
+func shouldEnrich(action string, cfg *Config) bool {
+	base, _, _ := strings.Cut(action, ":")
+	for _, a := range cfg.EnrichActions {
+		if a == action || a == base {
+			return true
+		}
+	}
+	return false
+}
+
+// inspect returns the container's inspect result, using the cache when it is fresh enough.
+func (c *inspectCache) inspect(id string, cfg *Config) (container.InspectResponse, error) {
+	ttl := defaultEnrichCacheTTL
+	if cfg.EnrichCacheSeconds > 0 {
+		ttl = time.Duration(cfg.EnrichCacheSeconds) * time.Second
+	}
+	now := time.Now()
+
+	c.mu.Lock()
+	for key, entry := range c.entries {
+		if now.Sub(entry.at) > ttl {
+			delete(c.entries, key)
+		}
+	}
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok {
+		return entry.resp, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), enrichTimeout)
+	defer cancel()
+	resp, err := inspector.ContainerInspect(ctx, id)
+	if err != nil {
+		return resp, err
+	}
+
+	c.mu.Lock()
+	c.entries[id] = cachedInspect{resp: resp, at: now}
+	c.mu.Unlock()
+	return resp, nil
+}
+
+// enrichFields inspects the container and describes its state. On failure it logs and returns
+// no fields so the notification is still sent un-enriched.
+func enrichFields(id string, cfg *Config) []embedField {
+	if inspector == nil || id == "" {
+		return nil
+	}
+	resp, err := inspections.inspect(id, cfg)
+	if err != nil {
+		slog.Warn("Failed to inspect container", "id", id, "error", err)
+		return nil
+	}
+	if resp.ContainerJSONBase == nil || resp.State == nil {
+		return nil
+	}
+
+	state := resp.State
+	fields := []embedField{
+		{Name: "State", Value: state.Status, Inline: true},
+		{Name: "Exit Code", Value: fmt.Sprintf("%d", state.ExitCode), Inline: true},
+		{Name: "Restarts", Value: fmt.Sprintf("%d", resp.RestartCount), Inline: true},
+	}
+	if state.OOMKilled {
+		fields = append(fields, embedField{Name: "OOM Killed", Value: "yes", Inline: true})
+	}
+	if state.Health != nil {
+		fields = append(fields, embedField{Name: "Health", Value: state.Health.Status, Inline: true})
+	}
+	if state.Error != "" {
+		fields = append(fields, embedField{Name: "Error", Value: state.Error})
+	}
+	return fields
+}
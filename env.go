@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables that override values from the config file.
+const (
+	envWebhook = "DOCKACORD_WEBHOOK"
+	envError   = "DOCKACORD_ERROR"
+	envWarning = "DOCKACORD_WARNING"
+	envInfo    = "DOCKACORD_INFO"
+)
+
+// envConfigured reports whether the environment provides everything needed to run without a
+// config file.
+func envConfigured() bool {
+	return os.Getenv(envWebhook) != ""
+}
+
+// applyOverrides overlays the environment and command-line flags onto the config. Action lists are
+// comma-separated, and setting one to an empty string disables that level.
+func applyOverrides(cfg *Config) {
+	if webhook := os.Getenv(envWebhook); webhook != "" {
+		cfg.Webhook = webhook
+	}
+	for name, actions := range map[string]*[]string{envError: &cfg.Error, envWarning: &cfg.Warning, envInfo: &cfg.Info} {
+		if value, ok := os.LookupEnv(name); ok {
+			*actions = splitList(value)
+		}
+	}
+	cfg.DryRun = cfg.DryRun || dryRunFlag
+}
+
+// splitList splits a comma-separated list, trimming spaces and dropping empty entries.
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testWebhook = "https://discord.com/api/webhooks/123/env-token"
+
+func TestLoadConfigFromEnvironmentWithoutFile(t *testing.T) {
+	t.Setenv(envWebhook, testWebhook)
+	t.Setenv(envError, "die, oom")
+	t.Setenv(envInfo, "")
+	filename := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := loadConfig(filename)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Webhook != testWebhook {
+		t.Errorf("Webhook = %q, want %q", cfg.Webhook, testWebhook)
+	}
+	if want := []string{"die", "oom"}; !slices.Equal(cfg.Error, want) {
+		t.Errorf("Error = %v, want %v", cfg.Error, want)
+	}
+	if !slices.Equal(cfg.Warning, defaultConfig.Warning) {
+		t.Errorf("Warning = %v, want the default %v", cfg.Warning, defaultConfig.Warning)
+	}
+	if len(cfg.Info) != 0 {
+		t.Errorf("Info = %v, want it disabled", cfg.Info)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("loadConfig() created %s, want no file when the environment is configured", filename)
+	}
+	if err := validateConfig(cfg); err != nil {
+		t.Errorf("validateConfig() error = %v", err)
+	}
+}
+
+func TestLoadConfigEnvironmentOverridesFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	file := `{"webhook": "https://discord.com/api/webhooks/1/file-token", "error": ["die"], "warning": ["kill"], "info": ["start"]}`
+	if err := os.WriteFile(filename, []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envWebhook, testWebhook)
+	t.Setenv(envWarning, "stop,pause")
+
+	cfg, err := loadConfig(filename)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if cfg.Webhook != testWebhook {
+		t.Errorf("Webhook = %q, want the environment's %q", cfg.Webhook, testWebhook)
+	}
+	if want := []string{"stop", "pause"}; !slices.Equal(cfg.Warning, want) {
+		t.Errorf("Warning = %v, want %v", cfg.Warning, want)
+	}
+	if want := []string{"die"}; !slices.Equal(cfg.Error, want) {
+		t.Errorf("Error = %v, want the file's %v", cfg.Error, want)
+	}
+	if want := []string{"start"}; !slices.Equal(cfg.Info, want) {
+		t.Errorf("Info = %v, want the file's %v", cfg.Info, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// sendEphemeral posts the payload and deletes the resulting message once ttl has passed.
+// Discord only returns the created message when the webhook is called with wait=true.
+func sendEphemeral(cfg *Config, webhookURL string, ttl time.Duration, payload map[string]interface{}) error {
+	waitURL, err := webhookMessageURL(webhookURL, "")
+	if err != nil {
+		return err
+	}
+	// Bypass the delivery queue, the message ID is needed right away.
+	body, err := send(cfg, waitURL, payload)
+	if err != nil {
+		return err
+	}
+
+	var message struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &message); err != nil || message.ID == "" {
+		log.Printf("Webhook did not return a message ID, it will not be deleted")
+		return nil
+	}
+	time.AfterFunc(ttl, func() { deleteWebhookMessage(cfg, webhookURL, message.ID) })
+	return nil
+}
+
+// webhookMessageURL returns the URL of a message sent through the webhook, or with an empty
+// messageID the webhook URL itself set to wait for the created message.
+func webhookMessageURL(webhookURL string, messageID string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook URL: %v", err)
+	}
+	if messageID == "" {
+		q := u.Query()
+		q.Set("wait", "true")
+		u.RawQuery = q.Encode()
+	} else {
+		u = u.JoinPath("messages", messageID)
+	}
+	return u.String(), nil
+}
+
+// deleteWebhookMessage removes a message previously sent through the webhook.
+func deleteWebhookMessage(cfg *Config, webhookURL string, messageID string) {
+	messageURL, err := webhookMessageURL(webhookURL, messageID)
+	if err != nil {
+		slog.Warn("Failed to delete message", "message_id", messageID, "error", err)
+		return
+	}
+	req, err := http.NewRequest(http.MethodDelete, messageURL, nil)
+	if err != nil {
+		slog.Warn("Failed to delete message", "message_id", messageID, "error", err)
+		return
+	}
+	setWebhookAuth(req, cfg)
+
+	resp, err := webhookClient(cfg).Do(req)
+	if err != nil {
+		slog.Warn("Failed to delete message", "message_id", messageID, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		slog.Warn("Failed to delete message", "message_id", messageID, "status", resp.StatusCode)
+		return
+	}
+	log.Printf("Deleted expired info message %s", messageID)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"sync"
+	"time"
+)
+
+// defaultEscalateWindow is used when escalation is enabled without an explicit window.
+const defaultEscalateWindow = 5 * time.Minute
+
+// escalator counts repeated warnings per host, container and action.
+type escalator struct {
+	mu     sync.Mutex
+	recent map[string][]time.Time
+}
+
+var escalations = escalator{recent: make(map[string][]time.Time)}
+
+// escalateWindow returns the configured escalation window.
+func escalateWindow(cfg *Config) time.Duration {
+	if cfg.EscalateWindowSeconds <= 0 {
+		return defaultEscalateWindow
+	}
+	return time.Duration(cfg.EscalateWindowSeconds) * time.Second
+}
+
+// record registers a warning and reports whether it should be escalated, along with the number of
+// earlier warnings that caused it. The count starts over after each escalation.
+func (e *escalator) record(host string, event events.Message, cfg *Config, now time.Time) (int, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	cutoff := now.Add(-escalateWindow(cfg))
+	for key, times := range e.recent {
+		if times = pruneBefore(times, cutoff); len(times) == 0 {
+			delete(e.recent, key)
+		} else {
+			e.recent[key] = times
+		}
+	}
+
+	key := host + "\x00" + event.Actor.Attributes["name"] + "\x00" + string(event.Action)
+	if count := len(e.recent[key]); count >= cfg.EscalateAfter {
+		delete(e.recent, key)
+		return count, true
+	}
+	e.recent[key] = append(e.recent[key], now)
+	return 0, false
+}
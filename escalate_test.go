@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"testing"
+	"time"
+)
+
+func TestEscalationCountsPerHost(t *testing.T) {
+	e := escalator{recent: make(map[string][]time.Time)}
+	cfg := &Config{EscalateAfter: 2}
+	event := events.Message{Action: "health_status: unhealthy", Actor: events.Actor{Attributes: map[string]string{"name": "web"}}}
+	now := time.Now()
+
+	// Two warnings of a same-named container on each host stay below the threshold per host.
+	for _, host := range []string{"web-01", "web-02", "web-01", "web-02"} {
+		if _, escalated := e.record(host, event, cfg, now); escalated {
+			t.Fatalf("warning from %s escalated, want hosts counted separately", host)
+		}
+	}
+	if count, escalated := e.record("web-01", event, cfg, now); !escalated || count != 2 {
+		t.Fatalf("third warning from web-01 = (%d, %v), want escalation after 2", count, escalated)
+	}
+}
+
+func TestHostFieldsOnlyForLocalDaemon(t *testing.T) {
+	event := events.Message{Type: events.NetworkEventType}
+	local := &Config{NetworkHostFields: []string{"hostname", "ip"}}
+	if got := hostFields(event, local); len(got) != 2 {
+		t.Fatalf("hostFields() = %v, want hostname and IP for the local daemon", got)
+	}
+	remote := &Config{NetworkHostFields: []string{"hostname", "ip"}, Hosts: []DockerHostConfig{{Name: "web-01"}, {Name: "web-02"}}}
+	if got := hostFields(event, remote); len(got) != 0 {
+		t.Fatalf("hostFields() = %v, want none when several hosts are watched", got)
+	}
+}
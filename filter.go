@@ -0,0 +1,131 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"path"
+	"strings"
+)
+
+// eventFilters builds the filters sent with the events subscription. With ServerSideFilters the
+// include lists are pushed down to the daemon when all their patterns are literal, since the
+// daemon only matches exact values. The daemon requires every label filter to match, so label
+// based lists are only pushed down when they hold a single entry. The daemon would apply the
+// container filters to every event type, so nothing is pushed down when other types are watched.
+func eventFilters(cfg *Config) filters.Args {
+	// Filter only the configured event types to reduce overhead
+	args := filters.NewArgs()
+	for _, t := range eventTypes(cfg) {
+		args.Add("type", t)
+	}
+	if !cfg.ServerSideFilters || !containersOnly(cfg) {
+		return args
+	}
+	if allLiteral(cfg.IncludeImages) {
+		for _, image := range cfg.IncludeImages {
+			args.Add("image", image)
+		}
+	}
+	if allLiteral(cfg.IncludeNames) {
+		for _, name := range cfg.IncludeNames {
+			args.Add("container", name)
+		}
+	}
+	if len(cfg.IncludeServices) == 1 && allLiteral(cfg.IncludeServices) {
+		args.Add("label", swarmServiceLabel+"="+cfg.IncludeServices[0])
+	}
+	if len(cfg.IncludeLabels) == 1 && allLiteral(cfg.IncludeLabels) {
+		args.Add("label", cfg.IncludeLabels[0])
+	}
+	return args
+}
+
+// allLiteral reports whether none of the patterns use glob syntax.
+func allLiteral(patterns []string) bool {
+	for _, p := range patterns {
+		if strings.ContainsAny(p, `*?[\`) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultIgnoreLabel is the container label that mutes a container's notifications when "true".
+const defaultIgnoreLabel = "dockacord.ignore"
+
+// muted reports whether the container opted out of notifications via the ignore label.
+// Actions listed in AlwaysNotify are never muted.
+func muted(event events.Message, cfg *Config) bool {
+	label := cfg.IgnoreLabel
+	if label == "" {
+		label = defaultIgnoreLabel
+	}
+	if event.Actor.Attributes[label] != "true" {
+		return false
+	}
+	for _, action := range cfg.AlwaysNotify {
+		if action == string(event.Action) {
+			return false
+		}
+	}
+	return true
+}
+
+// containerAllowed reports whether the container passes the name and label filters. A container is
+// included if no include list is set or it matches an entry of each set list, and an exclude match
+// always wins over an include match.
+func containerAllowed(event events.Message, cfg *Config) bool {
+	name := event.Actor.Attributes["name"]
+	if matchesAny(name, cfg.ExcludeNames) || matchesAnyLabel(event, cfg.ExcludeLabels) {
+		return false
+	}
+	if len(cfg.IncludeNames) > 0 && !matchesAny(name, cfg.IncludeNames) {
+		return false
+	}
+	if len(cfg.IncludeLabels) > 0 && !matchesAnyLabel(event, cfg.IncludeLabels) {
+		return false
+	}
+	return true
+}
+
+// matchesAnyLabel reports whether the event carries one of the labels. Each entry is either a
+// label key, matching any value, or key=value where the value may be a glob pattern.
+func matchesAnyLabel(event events.Message, labels []string) bool {
+	for _, label := range labels {
+		key, pattern, hasValue := strings.Cut(label, "=")
+		value, ok := event.Actor.Attributes[key]
+		if !ok {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// imageAllowed reports whether the event's image passes the include/exclude image patterns.
+// Events without an image attribute never match an include pattern.
+func imageAllowed(event events.Message, cfg *Config) bool {
+	image, ok := event.Actor.Attributes["image"]
+	if len(cfg.IncludeImages) > 0 && (!ok || !matchesAny(image, cfg.IncludeImages)) {
+		return false
+	}
+	if ok && matchesAny(image, cfg.ExcludeImages) {
+		return false
+	}
+	return true
+}
+
+// matchesAny reports whether s matches at least one of the glob patterns.
+func matchesAny(s string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"testing"
+)
+
+func TestContainerAllowed(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   Config
+		attrs map[string]string
+		want  bool
+	}{
+		{"no filters", Config{}, map[string]string{"name": "web"}, true},
+		{"name glob match", Config{IncludeNames: []string{"web-*"}}, map[string]string{"name": "web-1"}, true},
+		{"name glob miss", Config{IncludeNames: []string{"web-*"}}, map[string]string{"name": "db-1"}, false},
+		{"character class", Config{IncludeNames: []string{"web-[12]"}}, map[string]string{"name": "web-3"}, false},
+		{"excluded name", Config{ExcludeNames: []string{"*-test"}}, map[string]string{"name": "web-test"}, false},
+		{"label present", Config{IncludeLabels: []string{"monitor"}}, map[string]string{"name": "web", "monitor": ""}, true},
+		{"label missing", Config{IncludeLabels: []string{"monitor"}}, map[string]string{"name": "web"}, false},
+		{"label value glob", Config{IncludeLabels: []string{"env=prod*"}}, map[string]string{"name": "web", "env": "production"}, true},
+		{"label value miss", Config{IncludeLabels: []string{"env=prod*"}}, map[string]string{"name": "web", "env": "staging"}, false},
+		{"excluded label", Config{ExcludeLabels: []string{"env=dev"}}, map[string]string{"name": "web", "env": "dev"}, false},
+		{"exclude name wins over include", Config{IncludeNames: []string{"web-*"}, ExcludeNames: []string{"web-canary"}}, map[string]string{"name": "web-canary"}, false},
+		{"exclude label wins over include", Config{IncludeLabels: []string{"monitor"}, ExcludeLabels: []string{"skip"}}, map[string]string{"name": "web", "monitor": "", "skip": ""}, false},
+		{"needs both include lists", Config{IncludeNames: []string{"web-*"}, IncludeLabels: []string{"monitor"}}, map[string]string{"name": "web-1"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := events.Message{Type: events.ContainerEventType, Actor: events.Actor{Attributes: tt.attrs}}
+			if got := containerAllowed(event, &tt.cfg); got != tt.want {
+				t.Fatalf("containerAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
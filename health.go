@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// serverShutdownTimeout bounds how long in-flight probe and metrics requests may take on shutdown.
+const serverShutdownTimeout = 5 * time.Second
+
+// defaultHealthPingFailures is used when health_ping_failures is unset.
+const defaultHealthPingFailures = 3
+
+// streamHealth tracks the event streams of all hosts for the health endpoints.
+type streamHealth struct {
+	mu    sync.Mutex
+	hosts int
+	// up holds the hosts whose event stream is connected.
+	up map[string]bool
+	// subscribed holds the hosts whose first subscription succeeded.
+	subscribed map[string]bool
+	// failures counts the consecutive failed daemon pings per host.
+	failures map[string]int
+	// unreachable holds the hosts whose daemon failed enough pings in a row.
+	unreachable map[string]bool
+}
+
+var health = newStreamHealth()
+
+// newStreamHealth returns a tracker with no host connected yet.
+func newStreamHealth() streamHealth {
+	return streamHealth{
+		up:          make(map[string]bool),
+		subscribed:  make(map[string]bool),
+		failures:    make(map[string]int),
+		unreachable: make(map[string]bool),
+	}
+}
+
+// expect sets the number of hosts that must be connected to be healthy.
+func (h *streamHealth) expect(hosts int) {
+	h.mu.Lock()
+	h.hosts = hosts
+	h.mu.Unlock()
+}
+
+// connected marks the host's event stream as active.
+func (h *streamHealth) connected(host string) {
+	h.mu.Lock()
+	h.up[host] = true
+	h.subscribed[host] = true
+	h.mu.Unlock()
+}
+
+// disconnected marks the host's event stream as down until the next successful subscription.
+func (h *streamHealth) disconnected(host string) {
+	h.mu.Lock()
+	delete(h.up, host)
+	h.mu.Unlock()
+}
+
+// pinged records the result of a periodic daemon ping. The host only counts as unreachable after
+// limit consecutive failures, so a single slow ping does not flip the health status, and counts as
+// reachable again after the first successful ping.
+func (h *streamHealth) pinged(host string, err error, limit int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		delete(h.failures, host)
+		delete(h.unreachable, host)
+		return
+	}
+	h.failures[host]++
+	if h.failures[host] >= limit {
+		h.unreachable[host] = true
+	}
+}
+
+// healthy reports whether the event streams of all hosts are connected and no daemon failed too
+// many pings in a row.
+func (h *streamHealth) healthy() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hosts > 0 && len(h.up) == h.hosts && len(h.unreachable) == 0
+}
+
+// healthPingFailures returns how many consecutive failed pings mark a daemon unreachable.
+func healthPingFailures(cfg *Config) int {
+	if cfg.HealthPingFailures <= 0 {
+		return defaultHealthPingFailures
+	}
+	return cfg.HealthPingFailures
+}
+
+// watchDaemonHealth pings the daemon of every host each health_ping_seconds until ctx is cancelled
+// and records the results for /healthz.
+func watchDaemonHealth(ctx context.Context, hosts []DockerHostConfig, pingers []daemonPinger, cfg *Config) {
+	interval := time.Duration(cfg.HealthPingSeconds) * time.Second
+	limit := healthPingFailures(cfg)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for i, pinger := range pingers {
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			_, err := pinger.Ping(pingCtx)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("Docker daemon ping failed", hostAttrs(hosts[i].Name, "error", err)...)
+			}
+			health.pinged(hosts[i].Name, err, limit)
+		}
+	}
+}
+
+// ready reports whether every host has been subscribed to at least once.
+func (h *streamHealth) ready() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.hosts > 0 && len(h.subscribed) == h.hosts
+}
+
+// registerHealth adds the /healthz and /readyz endpoints to mux.
+func registerHealth(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", probeHandler(health.healthy, "event stream disconnected"))
+	mux.HandleFunc("/readyz", probeHandler(health.ready, "event stream not subscribed yet"))
+}
+
+// serveMuxes groups the enabled health and metrics endpoints by listen address, so both can share
+// one address.
+func serveMuxes(cfg *Config) map[string]*http.ServeMux {
+	muxes := make(map[string]*http.ServeMux)
+	mux := func(addr string) *http.ServeMux {
+		if muxes[addr] == nil {
+			muxes[addr] = http.NewServeMux()
+		}
+		return muxes[addr]
+	}
+	if cfg.HealthAddr != "" {
+		registerHealth(mux(cfg.HealthAddr))
+		if cfg.WebUI {
+			registerWebUI(mux(cfg.HealthAddr))
+		}
+	}
+	if cfg.MetricsAddr != "" {
+		registerMetrics(mux(cfg.MetricsAddr))
+	}
+	return muxes
+}
+
+// startHTTPServer serves handler on addr in the background.
+func startHTTPServer(addr string, handler http.Handler) *http.Server {
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "addr", addr, "error", err)
+		}
+	}()
+	log.Printf("HTTP server listening on %s", addr)
+	return srv
+}
+
+// probeHandler answers 200 while ok reports true and 503 with the reason otherwise.
+func probeHandler(ok func() bool, reason string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		if !ok() {
+			http.Error(w, reason, http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
+
+// stopHTTPServer shuts the server down, waiting briefly for in-flight requests.
+func stopHTTPServer(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("Failed to shut down HTTP server", "addr", srv.Addr, "error", err)
+	}
+}
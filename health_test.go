@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStreamHealthDebouncesPingFailures(t *testing.T) {
+	h := newStreamHealth()
+	h.expect(1)
+	h.connected("")
+	errPing := errors.New("ping failed")
+
+	steps := []struct {
+		err  error
+		want bool
+	}{
+		{errPing, true},
+		{errPing, true},
+		{errPing, false},
+		{errPing, false},
+		{nil, true},
+		{errPing, true},
+	}
+	for i, step := range steps {
+		h.pinged("", step.err, 3)
+		if got := h.healthy(); got != step.want {
+			t.Fatalf("after ping %d (error %v): healthy() = %v, want %v", i+1, step.err, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"net"
+	"os"
+	"sync"
+)
+
+// hostInfo is the hostname and primary IP of the machine DockaCord runs on, resolved once.
+var hostInfo = sync.OnceValues(func() (string, string) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname, primaryIP()
+})
+
+// primaryIP returns the first non-loopback IPv4 address of the host, or "unknown".
+func primaryIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "unknown"
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return "unknown"
+}
+
+// hostFields adds the configured host details to network events so they can be attributed to a host.
+// When several hosts are watched the events come from other machines, so the Docker Host field
+// names their origin instead.
+func hostFields(event events.Message, cfg *Config) []embedField {
+	if event.Type != events.NetworkEventType || len(cfg.Hosts) > 0 {
+		return nil
+	}
+	hostname, ip := hostInfo()
+	var fields []embedField
+	for _, field := range cfg.NetworkHostFields {
+		switch field {
+		case "hostname":
+			fields = append(fields, embedField{Name: "Host", Value: hostname, Inline: true})
+		case "ip":
+			fields = append(fields, embedField{Name: "Host IP", Value: ip, Inline: true})
+		}
+	}
+	return fields
+}
+
+// validateHostFields rejects unknown network host fields.
+func validateHostFields(cfg *Config) error {
+	for _, field := range cfg.NetworkHostFields {
+		if field != "hostname" && field != "ip" {
+			return fmt.Errorf("invalid network_host_fields entry %q: must be hostname or ip", field)
+		}
+	}
+	return nil
+}
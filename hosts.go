@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"io"
+	"log"
+	"log/slog"
+	"time"
+)
+
+// DockerHostConfig describes a Docker daemon to watch. Host and the TLS files fall back to the
+// DOCKER_* environment variables when empty.
+type DockerHostConfig struct {
+	// Name labels the host's notifications, e.g. "web-01".
+	Name    string `json:"name"`
+	Host    string `json:"host,omitempty"`
+	TLSCA   string `json:"tls_ca,omitempty"`
+	TLSCert string `json:"tls_cert,omitempty"`
+	TLSKey  string `json:"tls_key,omitempty"`
+}
+
+// dockerHosts returns the configured hosts, or a single unnamed host built from docker_host and the
+// docker_tls_* settings. A nil config uses the environment only.
+func dockerHosts(cfg *Config) []DockerHostConfig {
+	if cfg == nil {
+		return []DockerHostConfig{{}}
+	}
+	if len(cfg.Hosts) > 0 {
+		return cfg.Hosts
+	}
+	return []DockerHostConfig{{Host: cfg.DockerHost, TLSCA: cfg.DockerTLSCA, TLSCert: cfg.DockerTLSCert, TLSKey: cfg.DockerTLSKey}}
+}
+
+// validateHosts requires distinct names for multiple hosts and complete client certificates.
+func validateHosts(cfg *Config) error {
+	names := make(map[string]bool)
+	for _, host := range dockerHosts(cfg) {
+		if len(cfg.Hosts) > 1 {
+			if host.Name == "" {
+				return fmt.Errorf("every entry in hosts needs a name")
+			}
+			if names[host.Name] {
+				return fmt.Errorf("duplicate host name %q in hosts", host.Name)
+			}
+			names[host.Name] = true
+		}
+		if (host.TLSCert == "") != (host.TLSKey == "") {
+			return fmt.Errorf("the TLS certificate and key of host %q must be set together", host.Name)
+		}
+	}
+	return nil
+}
+
+// newDockerClient creates a Docker client from the environment, overridden by the host's address
+// and TLS files.
+func newDockerClient(host DockerHostConfig) (*client.Client, error) {
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if host.Host != "" {
+		opts = append(opts, client.WithHost(host.Host))
+	}
+	if host.TLSCA != "" || host.TLSCert != "" {
+		opts = append(opts, client.WithTLSClientConfig(host.TLSCA, host.TLSCert, host.TLSKey))
+	}
+	return client.NewClientWithOpts(opts...)
+}
+
+// dockerClients serves enrichment and log tailing across all hosts. Container IDs are unique, so
+// the first host that knows a container answers for it.
+type dockerClients []*client.Client
+
+// ContainerInspect inspects the container on the first host that has it.
+func (c dockerClients) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	var resp container.InspectResponse
+	err := fmt.Errorf("no Docker host configured")
+	for _, cli := range c {
+		if resp, err = cli.ContainerInspect(ctx, containerID); err == nil {
+			return resp, nil
+		}
+	}
+	return resp, err
+}
+
+// ContainerList lists the containers of every reachable host.
+func (c dockerClients) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	var all []container.Summary
+	var lastErr error
+	for _, cli := range c {
+		containers, err := cli.ContainerList(ctx, options)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		all = append(all, containers...)
+	}
+	if all == nil && lastErr != nil {
+		return nil, lastErr
+	}
+	return all, nil
+}
+
+// ContainerLogs streams the logs of the container from the first host that has it.
+func (c dockerClients) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+	err := fmt.Errorf("no Docker host configured")
+	for _, cli := range c {
+		var rc io.ReadCloser
+		if rc, err = cli.ContainerLogs(ctx, containerID, options); err == nil {
+			return rc, nil
+		}
+	}
+	return nil, err
+}
+
+// hostEvent is an event together with the name of the host that reported it.
+type hostEvent struct {
+	host  string
+	event events.Message
+}
+
+// subscriber returns a function that subscribes to the host's event stream. The events request is
+// made in the background, so the daemon is pinged first to learn whether it is reachable before the
+// stream is reported as healthy.
+func subscriber(ctx context.Context, name string, cli *client.Client, filterArgs filters.Args) func() (<-chan events.Message, <-chan error) {
+	return func() (<-chan events.Message, <-chan error) {
+		if _, err := cli.Ping(ctx); err != nil {
+			errs := make(chan error, 1)
+			errs <- err
+			return nil, errs
+		}
+		health.connected(name)
+		return cli.Events(ctx, events.ListOptions{
+			Filters: filterArgs,
+		})
+	}
+}
+
+// streamEvents forwards the host's events to out. When the event stream ends it is re-established
+// with backoff until ctx is cancelled.
+func streamEvents(ctx context.Context, name string, subscribe func() (<-chan events.Message, <-chan error), out chan<- hostEvent, backoff BackoffConfig) {
+	msgs, errs := subscribe()
+	var reconnect <-chan time.Time
+	attempt := 0
+	streamEnded := func(reason string) {
+		health.disconnected(name)
+		attempt++
+		delay := backoff.wait(attempt)
+		slog.Warn("Docker event stream ended, reconnecting", hostAttrs(name, "reason", reason, "delay", delay)...)
+		msgs, errs = nil, nil
+		reconnect = time.After(delay)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-msgs:
+			if !ok {
+				streamEnded("event channel closed")
+				continue
+			}
+			attempt = 0
+			select {
+			case out <- hostEvent{host: name, event: event}:
+			case <-ctx.Done():
+				return
+			}
+		case err, ok := <-errs:
+			if ctx.Err() != nil {
+				return
+			}
+			if !ok || err == nil {
+				streamEnded("error channel closed")
+				continue
+			}
+			slog.Error("Error receiving Docker event", hostAttrs(name, "error", err)...)
+			streamEnded(err.Error())
+		case <-reconnect:
+			reconnect = nil
+			log.Printf("Reconnecting to the Docker event stream%s", hostSuffix(name))
+			msgs, errs = subscribe()
+		}
+	}
+}
+
+// hostSuffix names the host in log messages when there is more than one.
+func hostSuffix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " of host " + name
+}
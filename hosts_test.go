@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"github.com/docker/docker/api/types/events"
+	"testing"
+	"time"
+)
+
+func TestStreamEventsReconnectsWhenChannelCloses(t *testing.T) {
+	subscriptions := 0
+	subscribe := func() (<-chan events.Message, <-chan error) {
+		subscriptions++
+		msgs := make(chan events.Message, 1)
+		msgs <- events.Message{Action: events.Action("start"), TimeNano: int64(subscriptions)}
+		if subscriptions == 1 {
+			// The first stream delivers one event and then ends.
+			close(msgs)
+		}
+		return msgs, make(chan error)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := make(chan hostEvent)
+	go streamEvents(ctx, "web-01", subscribe, out, BackoffConfig{Strategy: "constant", BaseMs: 10})
+
+	for want := int64(1); want <= 2; want++ {
+		select {
+		case got := <-out:
+			if got.host != "web-01" || got.event.TimeNano != want {
+				t.Fatalf("got event %d from %q, want event %d from web-01", got.event.TimeNano, got.host, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for event %d", want)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultRequestTimeout is used when request_timeout_seconds is unset.
+const defaultRequestTimeout = 10 * time.Second
+
+// defaultHTTPClient is used for configs that were not validated, which never happens at runtime.
+var defaultHTTPClient = &http.Client{Timeout: defaultRequestTimeout}
+
+// buildHTTPClient creates the client shared by all webhook requests, with the configured timeout
+// and proxy. Without proxy_url the HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables are honored.
+func buildHTTPClient(cfg *Config) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return fmt.Errorf("invalid proxy_url %q", cfg.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if cfg.RequestTimeoutSeconds < 0 {
+		return fmt.Errorf("request_timeout_seconds must not be negative")
+	}
+	timeout := defaultRequestTimeout
+	if cfg.RequestTimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.RequestTimeoutSeconds) * time.Second
+	}
+	cfg.httpClient = &http.Client{Timeout: timeout, Transport: transport}
+	return nil
+}
+
+// webhookClient returns the HTTP client for webhook requests.
+func webhookClient(cfg *Config) *http.Client {
+	if cfg.httpClient == nil {
+		return defaultHTTPClient
+	}
+	return cfg.httpClient
+}
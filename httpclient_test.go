@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWebhookRequestTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cfg := &Config{RequestTimeoutSeconds: 1}
+	if err := buildHTTPClient(cfg); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	_, err := postWebhook(cfg, srv.URL, map[string]string{"content": "hello"})
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("request took %s, want it cut off after the 1s timeout", elapsed)
+	}
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || !urlErr.Timeout() {
+		t.Fatalf("postWebhook() error = %v, want a timeout", err)
+	}
+	if !retryableWebhookError(err) {
+		t.Fatalf("retryableWebhookError(%v) = false, want timeouts to be retried", err)
+	}
+}
+
+func TestBuildHTTPClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		timeout time.Duration
+		wantErr bool
+	}{
+		{"default timeout", Config{}, defaultRequestTimeout, false},
+		{"configured timeout", Config{RequestTimeoutSeconds: 3}, 3 * time.Second, false},
+		{"negative timeout", Config{RequestTimeoutSeconds: -1}, 0, true},
+		{"proxy", Config{ProxyURL: "http://proxy.internal:3128"}, defaultRequestTimeout, false},
+		{"invalid proxy", Config{ProxyURL: "proxy"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := buildHTTPClient(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildHTTPClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.cfg.httpClient.Timeout != tt.timeout {
+				t.Fatalf("timeout = %s, want %s", tt.cfg.httpClient.Timeout, tt.timeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LevelConfig defines a custom level with its own embed color and actions.
+type LevelConfig struct {
+	Color   int      `json:"color"`
+	Actions []string `json:"actions"`
+}
+
+// levelActions returns the actions of every level, built-in and custom.
+func levelActions(cfg *Config) map[string][]string {
+	actions := map[string][]string{
+		"error":   cfg.Error,
+		"warning": cfg.Warning,
+		"info":    cfg.Info,
+	}
+	for name, level := range cfg.Levels {
+		actions[name] = level.Actions
+	}
+	return actions
+}
+
+// isLevel reports whether level is a built-in level or a custom level of the config.
+func isLevel(level string, cfg *Config) bool {
+	switch level {
+	case "error", "warning", "info":
+		return true
+	}
+	_, ok := cfg.Levels[level]
+	return ok
+}
+
+// validateLevels rejects custom levels that reuse a reserved name or have an invalid color, and
+// actions that are mapped to more than one level.
+func validateLevels(cfg *Config) error {
+	for name, level := range cfg.Levels {
+		switch name {
+		case "", "error", "warning", "info", "ignore":
+			return fmt.Errorf("invalid level name %q: the name is reserved", name)
+		}
+		if level.Color < 0 || level.Color > 0xFFFFFF {
+			return fmt.Errorf("invalid color %d for level %q: must be between 0 and 16777215", level.Color, name)
+		}
+	}
+
+	actions := levelActions(cfg)
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]string)
+	for _, name := range names {
+		for _, action := range actions[name] {
+			if other, ok := seen[action]; ok && other != name {
+				return fmt.Errorf("action %q is mapped to both %q and %q", action, other, name)
+			}
+			seen[action] = name
+		}
+	}
+	return nil
+}
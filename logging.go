@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+)
+
+// The standard loggers as set up before any handler was installed, restored when a reload removes
+// log_format and log_level.
+var (
+	stdLogger    = slog.Default()
+	stdLogOutput = log.Writer()
+	stdLogFlags  = log.Flags()
+)
+
+// setupLogging installs a slog handler with the configured format and minimum level. Messages
+// written with the log package go through the same handler at info level. Without log_format or
+// log_level the standard log output is used.
+func setupLogging(cfg *Config) {
+	if cfg.LogFormat == "" && cfg.LogLevel == "" {
+		slog.SetDefault(stdLogger)
+		log.SetOutput(stdLogOutput)
+		log.SetFlags(stdLogFlags)
+		return
+	}
+	var level slog.Level
+	// The level was checked by validateConfig.
+	_ = level.UnmarshalText([]byte(cfg.LogLevel))
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, opts)
+	if cfg.LogFormat == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+// validateLogging rejects unknown log formats and levels.
+func validateLogging(cfg *Config) error {
+	switch cfg.LogFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("invalid log_format %q: must be text or json", cfg.LogFormat)
+	}
+	if cfg.LogLevel != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+			return fmt.Errorf("invalid log_level %q: must be debug, info, warn or error", cfg.LogLevel)
+		}
+	}
+	return nil
+}
+
+// hostAttrs adds the Docker host to the attributes when several hosts are watched.
+func hostAttrs(name string, attrs ...any) []any {
+	if name == "" {
+		return attrs
+	}
+	return append([]any{"host", name}, attrs...)
+}
+
+// fatal logs the message as an error, so it is shown at every log level, and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// notificationAttrs returns the attributes identifying a notification in structured logs. The level
+// is logged as event_level so it does not clash with the record's own level.
+func notificationAttrs(n notification) []any {
+	attrs := []any{"container", resourceName(n.event), "action", actionKey(n.event), "event_level", n.level}
+	if n.host != "" {
+		attrs = append(attrs, "host", n.host)
+	}
+	return attrs
+}
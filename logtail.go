@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/stdcopy"
+	"io"
+	"log"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Log tailing defaults.
+const (
+	defaultLogTailMatch = `(?i)\b(error|fatal|panic)\b`
+	logTailFlushDelay   = 2 * time.Second
+	logTailMaxLines     = 10
+	logTailMaxLineLen   = 300
+)
+
+// LogTailConfig forwards matching log lines of containers whose name matches one of Containers.
+type LogTailConfig struct {
+	// Containers are glob patterns matched against the container name.
+	Containers []string `json:"containers"`
+	// Match is a regular expression selecting the lines to forward (default: error, fatal or panic).
+	Match string `json:"match,omitempty"`
+	// Level is the notification level for forwarded lines (default "error").
+	Level string `json:"level,omitempty"`
+}
+
+// logSource is the part of the Docker client used to tail container logs.
+type logSource interface {
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+}
+
+// logReader is set in main once the Docker client has been created.
+var logReader logSource
+
+// logTailer keeps at most one log stream per container.
+type logTailer struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	active map[string]bool
+}
+
+var logTails = logTailer{active: make(map[string]bool)}
+
+// matchLogTail returns the first log tail rule matching the container name, if any.
+func matchLogTail(name string, cfg *Config) *LogTailConfig {
+	for i := range cfg.LogTail {
+		if matchesAny(name, cfg.LogTail[i].Containers) {
+			return &cfg.LogTail[i]
+		}
+	}
+	return nil
+}
+
+// startLogTails attaches to the running containers that match a log tail rule. Streams stop when
+// ctx is cancelled.
+func startLogTails(ctx context.Context, cfg *Config) {
+	if len(cfg.LogTail) == 0 || logReader == nil {
+		return
+	}
+	logTails.mu.Lock()
+	logTails.ctx = ctx
+	logTails.mu.Unlock()
+
+	containers, err := logReader.ContainerList(ctx, container.ListOptions{})
+	if err != nil {
+		slog.Error("Failed to list containers for log tailing", "error", err)
+		return
+	}
+	for _, c := range containers {
+		if len(c.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(c.Names[0], "/")
+		if rule := matchLogTail(name, cfg); rule != nil {
+			logTails.attach(c.ID, name, time.Now(), rule, cfg)
+		}
+	}
+}
+
+// observe re-attaches to a matching container whenever it starts.
+func (t *logTailer) observe(event events.Message, cfg *Config) {
+	if len(cfg.LogTail) == 0 || event.Action != events.ActionStart {
+		return
+	}
+	name := event.Actor.Attributes["name"]
+	if rule := matchLogTail(name, cfg); rule != nil {
+		t.attach(event.Actor.ID, name, eventTime(event), rule, cfg)
+	}
+}
+
+// attach starts following the container's logs from since, unless it is already being followed.
+func (t *logTailer) attach(id string, name string, since time.Time, rule *LogTailConfig, cfg *Config) {
+	t.mu.Lock()
+	if t.ctx == nil || t.active[id] {
+		t.mu.Unlock()
+		return
+	}
+	t.active[id] = true
+	ctx := t.ctx
+	t.mu.Unlock()
+
+	log.Printf("Tailing logs of container %s", name)
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.active, id)
+			t.mu.Unlock()
+		}()
+		if err := followLogs(ctx, id, name, since, rule, cfg); err != nil {
+			log.Printf("Log tail for container %s ended: %v", name, err)
+		}
+	}()
+}
+
+// followLogs streams the container's logs until it stops, forwarding matching lines in small batches.
+func followLogs(ctx context.Context, id string, name string, since time.Time, rule *LogTailConfig, cfg *Config) error {
+	pattern := rule.Match
+	if pattern == "" {
+		pattern = defaultLogTailMatch
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	tty := false
+	if inspector != nil {
+		if resp, err := inspector.ContainerInspect(ctx, id); err == nil && resp.Config != nil {
+			tty = resp.Config.Tty
+		}
+	}
+
+	rc, err := logReader.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Since:      strconv.FormatInt(since.Unix(), 10),
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	// Non-TTY containers multiplex stdout and stderr into one stream.
+	var r io.Reader = rc
+	if !tty {
+		pr, pw := io.Pipe()
+		go func() {
+			_, err := stdcopy.StdCopy(pw, pw, rc)
+			pw.CloseWithError(err)
+		}()
+		r = pr
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			if line := scanner.Text(); re.MatchString(line) {
+				lines <- line
+			}
+		}
+	}()
+
+	var batch []string
+	flush := func() {
+		if len(batch) > 0 {
+			held := batch
+			sender.submit(func() { sendLogLines(name, held, rule, currentConfig(cfg)) })
+			batch = nil
+		}
+	}
+	timer := time.NewTimer(logTailFlushDelay)
+	timer.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush()
+				return nil
+			}
+			if len(line) > logTailMaxLineLen {
+				line = line[:logTailMaxLineLen] + "…"
+			}
+			if len(batch) == 0 {
+				timer.Reset(logTailFlushDelay)
+			}
+			batch = append(batch, line)
+			if len(batch) >= logTailMaxLines {
+				timer.Stop()
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// sendLogLines posts forwarded log lines of one container as a single embed.
+func sendLogLines(name string, lines []string, rule *LogTailConfig, cfg *Config) {
+	level := rule.Level
+	if level == "" {
+		level = "error"
+	}
+	description := fmt.Sprintf("**Container**: `%s`\n```\n%s\n```", name, strings.ReplaceAll(strings.Join(lines, "\n"), "```", "'''"))
+	embed := newEmbed(fmt.Sprintf("Container Log - %s", strings.ToUpper(level)), description, level, cfg)
+	if err := sendEmbeds(cfg, webhookFor(level, cfg), embed); err != nil {
+		slog.Error("Failed to send log lines", "container", name, "error", err)
+	}
+}
@@ -4,181 +4,902 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/docker/docker/api/types/events"
-	"github.com/docker/docker/api/types/filters"
-	"github.com/docker/docker/client"
 	"io"
 	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
-	"syscall"
+	"sync/atomic"
+	"text/template"
+	"time"
+	_ "time/tzdata"
 )
 
 // Config represents the JSON structure users can define in config.json.
 type Config struct {
-	Webhook string   `json:"webhook"`
+	Webhook string `json:"webhook"`
+	// Webhooks optionally routes levels to their own webhook, e.g. {"error": "..."}. Levels without
+	// an entry use Webhook.
+	Webhooks map[string]string `json:"webhooks,omitempty"`
+	// WebhooksDir reads webhooks from a directory of files, such as a mounted Kubernetes secret:
+	// each file is named after a level, or "default" for Webhook, and holds the webhook URL. The
+	// files take precedence over webhook and webhooks, and changes to them are reloaded.
+	WebhooksDir string `json:"webhooks_dir,omitempty"`
+	// TypeWebhooks routes event types to their own webhook, e.g. {"image": "..."}. A type's webhook
+	// takes precedence over the level's, and a policy rule's webhook over both.
+	TypeWebhooks map[string]string `json:"type_webhooks,omitempty"`
+	// Mentions pings roles or users on notifications of a level, e.g. {"error": "<@&123456>"}.
+	// Only the listed roles and users are pinged.
+	Mentions map[string]string `json:"mentions,omitempty"`
+	// Provider selects the webhook format: "discord" (default) or "slack" for Slack incoming
+	// webhooks. Mentions and info_message_ttl_seconds are Discord only.
+	Provider string `json:"provider,omitempty"`
+	// Error, Warning and Info list the actions notified at each level. The interactive actions
+	// attach, detach and resize are ignored entirely unless listed here.
 	Error   []string `json:"error"`
 	Warning []string `json:"warning"`
 	Info    []string `json:"info"`
+	// Types lists the Docker event types to watch (default ["container"]). Actions of other types
+	// are listed with their type, e.g. "image:pull" or "volume:destroy". Changes need a restart.
+	Types []string `json:"types,omitempty"`
+	// Levels defines additional named levels with their own color and actions, e.g.
+	// {"critical": {"color": 9109504, "actions": ["oom"]}}. An action may only belong to one level.
+	Levels map[string]LevelConfig `json:"levels,omitempty"`
+
+	// IncludeNames and ExcludeNames are glob patterns (e.g. "web-*") matched against the container name.
+	// IncludeLabels and ExcludeLabels match container labels as "key" or "key=value" (value may be a glob).
+	// Exclusions win over inclusions.
+	IncludeNames  []string `json:"include_names,omitempty"`
+	ExcludeNames  []string `json:"exclude_names,omitempty"`
+	IncludeLabels []string `json:"include_labels,omitempty"`
+	ExcludeLabels []string `json:"exclude_labels,omitempty"`
+
+	// IncludeImages and ExcludeImages are glob patterns (e.g. "myorg/*") matched against the container image.
+	IncludeImages []string `json:"include_images,omitempty"`
+	ExcludeImages []string `json:"exclude_images,omitempty"`
+
+	// DeployWindowSeconds suppresses non-error notifications for this long after a container
+	// carrying DeployLabel=true emits an event. Zero disables deployment windows.
+	DeployWindowSeconds int    `json:"deploy_window_seconds,omitempty"`
+	DeployLabel         string `json:"deploy_label,omitempty"`
+
+	// QuietHours drops notifications of selected levels during scheduled windows, e.g. overnight.
+	QuietHours []QuietHours `json:"quiet_hours,omitempty"`
+
+	// TimestampSource selects which time is shown: "event" (default), "receive", or "both".
+	TimestampSource string `json:"timestamp_source,omitempty"`
+	// TimestampNano sets the embed timestamp with the event's nanosecond precision.
+	TimestampNano bool `json:"timestamp_nano,omitempty"`
+	// TimestampStyle selects how times are written in the description: "discord" (default) uses
+	// Discord's dynamic tags, "absolute" a fixed string in FooterTimeFormat and FooterTimeZone,
+	// and "both" shows the fixed string followed by the tags.
+	TimestampStyle string `json:"timestamp_style,omitempty"`
+
+	// ErrorBurstThreshold switches error notifications to a single summary per window once more
+	// than this many errors arrive within ErrorBurstWindowSeconds. Zero disables burst summaries.
+	ErrorBurstThreshold     int `json:"error_burst_threshold,omitempty"`
+	ErrorBurstWindowSeconds int `json:"error_burst_window_seconds,omitempty"`
+
+	// AllowedWebhookHosts restricts the webhooks to these hosts (glob patterns such as "*.discord.com").
+	// Without it, webhooks must be Discord webhook URLs.
+	AllowedWebhookHosts []string `json:"allowed_webhook_hosts,omitempty"`
+
+	// EscalateAfter promotes a warning to error once the same container and action has already
+	// produced this many warnings within EscalateWindowSeconds. Zero disables escalation.
+	EscalateAfter         int `json:"escalate_after,omitempty"`
+	EscalateWindowSeconds int `json:"escalate_window_seconds,omitempty"`
+
+	// ThrottleSeconds holds each container's notifications for this long and then sends only the
+	// most recent one. ThrottleActions limits this to the listed actions; empty means all.
+	ThrottleSeconds int      `json:"throttle_seconds,omitempty"`
+	ThrottleActions []string `json:"throttle_actions,omitempty"`
+
+	// IncludeRunID adds this process's run ID (DOCKACORD_RUN_ID or a random ID) to every embed footer.
+	IncludeRunID bool `json:"include_run_id,omitempty"`
+
+	// Backoff controls the delay between retries.
+	Backoff BackoffConfig `json:"backoff"`
+
+	// WebhookUsername and WebhookPassword enable HTTP Basic Auth for receivers behind an auth gateway.
+	// The *File variants read the value from a file such as a mounted secret.
+	WebhookUsername     string `json:"webhook_username,omitempty"`
+	WebhookUsernameFile string `json:"webhook_username_file,omitempty"`
+	WebhookPassword     string `json:"webhook_password,omitempty"`
+	WebhookPasswordFile string `json:"webhook_password_file,omitempty"`
+	// WebhookHeaders are added to every webhook request, e.g. a bearer token in Authorization.
+	WebhookHeaders map[string]string `json:"webhook_headers,omitempty"`
+
+	// EnrichActions lists the actions that trigger a container inspect to add state details.
+	// Results are cached for EnrichCacheSeconds (default 5).
+	EnrichActions      []string `json:"enrich_actions,omitempty"`
+	EnrichCacheSeconds int      `json:"enrich_cache_seconds,omitempty"`
+
+	// DeadLetterPath stores notifications that could not be delivered in a JSON-lines file, replayed
+	// after the next successful delivery. DeadLetterMax caps its entries (default 100).
+	DeadLetterPath string `json:"dead_letter_path,omitempty"`
+	DeadLetterMax  int    `json:"dead_letter_max,omitempty"`
+
+	// CrashLoopRestarts sends one crash-loop alert when a container dies more than this many times
+	// within CrashLoopWindowSeconds (default 300), then suppresses its start/die notifications until
+	// it stays up for a full window. Zero disables crash-loop detection.
+	CrashLoopRestarts      int `json:"crash_loop_restarts,omitempty"`
+	CrashLoopWindowSeconds int `json:"crash_loop_window_seconds,omitempty"`
+
+	// ShowEventType adds the event's object type (container, image, ...) and scope as embed fields.
+	ShowEventType bool `json:"show_event_type,omitempty"`
+
+	// RefusePlaceholderWebhook exits at startup instead of only logging when the webhook is still the placeholder.
+	RefusePlaceholderWebhook bool `json:"refuse_placeholder_webhook,omitempty"`
+
+	// FooterTimeFormat appends the event time to the footer using this Go time layout
+	// (e.g. "2006-01-02 15:04:05 MST") in FooterTimeZone (IANA name, default UTC). Both also
+	// apply to absolute timestamps selected with TimestampStyle.
+	FooterTimeFormat string `json:"footer_time_format,omitempty"`
+	FooterTimeZone   string `json:"footer_time_zone,omitempty"`
+	// timeLocation is FooterTimeZone resolved by validateConfig.
+	timeLocation *time.Location
+
+	// LogTail continuously forwards matching log lines of selected containers. Opt-in only, as busy
+	// containers can produce a lot of messages.
+	LogTail []LogTailConfig `json:"log_tail,omitempty"`
+
+	// EmbedAttributes lists event attributes (e.g. "image", "exitCode") to show in notifications.
+	// AttributeRenderMode lays them out, together with any other details, as "fields" (default),
+	// an aligned "table" in a code block, or "inline" description lines.
+	EmbedAttributes     []string `json:"embed_attributes,omitempty"`
+	AttributeRenderMode string   `json:"attribute_render_mode,omitempty"`
+
+	// EmbedAuthorEnabled includes the "Notification Bot" author block in embeds (default true).
+	EmbedAuthorEnabled *bool `json:"embed_author_enabled,omitempty"`
+
+	// IncludeSequence adds each event's sequence number to the footer so gaps can be spotted downstream.
+	IncludeSequence bool `json:"include_sequence,omitempty"`
+
+	// InfoMessageTTLSeconds deletes info-level messages from the channel after this long.
+	// Zero keeps them. Pending deletions are only held in memory, so messages whose TTL has not
+	// passed when DockaCord stops or restarts stay in the channel.
+	InfoMessageTTLSeconds int `json:"info_message_ttl_seconds,omitempty"`
+
+	// Signals maps signal names (e.g. "SIGHUP") to "shutdown", "exit" (immediately), "reload" or
+	// "ignore". SIGINT and SIGTERM shut down unless configured otherwise.
+	Signals map[string]string `json:"signals,omitempty"`
+
+	// IncludeServices only notifies for Swarm services whose name matches one of these glob patterns.
+	IncludeServices []string `json:"include_services,omitempty"`
+
+	// ServerSideFilters lets the daemon apply the include lists where possible,
+	// reducing the events DockaCord receives on busy hosts. Log tailing then only sees those events.
+	ServerSideFilters bool `json:"server_side_filters,omitempty"`
+
+	// IgnoreLabel mutes containers that carry it set to "true" (default "dockacord.ignore"), except
+	// for actions listed in AlwaysNotify.
+	IgnoreLabel  string   `json:"ignore_label,omitempty"`
+	AlwaysNotify []string `json:"always_notify,omitempty"`
+
+	// QueuePath persists outgoing notifications in a JSON-lines file and delivers them from there,
+	// retrying with Backoff, so they survive webhook outages and restarts. QueueMax caps its
+	// entries (default 1000).
+	QueuePath string `json:"queue_path,omitempty"`
+	QueueMax  int    `json:"queue_max,omitempty"`
+
+	// SuccessStatusCodes are the webhook response codes treated as delivered (default 200 and 204).
+	SuccessStatusCodes []int `json:"success_status_codes,omitempty"`
+
+	// NetworkHostFields adds details of the DockaCord host ("hostname", "ip") to network events.
+	// It is ignored when Hosts is set, as the events then come from other machines.
+	NetworkHostFields []string `json:"network_host_fields,omitempty"`
+
+	// PolicyPath loads classification rules from a separate JSON file that is watched and hot-reloaded.
+	// Its rules take precedence over the error, warning and info lists.
+	PolicyPath string `json:"policy_path,omitempty"`
+
+	// ImageAggregateSeconds groups notifications by image for this long and sends one summary
+	// naming the image and the affected containers. Zero disables aggregation.
+	ImageAggregateSeconds int `json:"image_aggregate_seconds,omitempty"`
+
+	// BatchWindowMs collects notifications arriving within this window into one message of up to
+	// 10 embeds. Zero sends every notification on its own.
+	BatchWindowMs int `json:"batch_window_ms,omitempty"`
+	// BatchWindowsMs sets the window per level instead, e.g. {"error": 0, "info": 10000}. Levels
+	// without an entry use BatchWindowMs; zero sends the level's notifications right away.
+	BatchWindowsMs map[string]int `json:"batch_windows_ms,omitempty"`
+
+	// HealthAddr serves /healthz (event stream connected) and /readyz (first subscription done) on
+	// this address, e.g. ":8080". Empty disables the health server.
+	HealthAddr string `json:"health_addr,omitempty"`
+
+	// WebUI also serves a live table of recent events on HealthAddr at /ui, and the events as
+	// JSON at /recent.
+	WebUI bool `json:"web_ui,omitempty"`
+
+	// HealthPingSeconds also pings every Docker daemon this often and reports /healthz unhealthy
+	// after HealthPingFailures consecutive failed pings (default 3), until the next successful
+	// ping. Zero disables the ping.
+	HealthPingSeconds  int `json:"health_ping_seconds,omitempty"`
+	HealthPingFailures int `json:"health_ping_failures,omitempty"`
+
+	// MetricsAddr serves Prometheus metrics on /metrics at this address. It may equal HealthAddr.
+	// Empty disables the metrics endpoint.
+	MetricsAddr string `json:"metrics_addr,omitempty"`
+
+	// ShutdownTimeoutSeconds bounds how long shutdown waits for in-flight notifications (default 10).
+	ShutdownTimeoutSeconds int `json:"shutdown_timeout_seconds,omitempty"`
+
+	// DryRun prints every payload as indented JSON to stdout instead of posting it, bypassing the
+	// delivery and dead-letter queues. The -dry-run flag forces it on.
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Templates customizes the title and description of event notifications per level with
+	// text/template, e.g. {"error": {"title": "{{.ContainerName}} {{.Action}}"}}. Templates see
+	// ContainerName, Host, Type, Action, Level, Time, Image, ID and Attributes.
+	Templates map[string]MessageTemplate `json:"templates,omitempty"`
+
+	// LogFormat is "text" or "json" for structured logs, and LogLevel their minimum level: debug,
+	// info (default), warn or error. Debug also logs every received event.
+	LogFormat string `json:"log_format,omitempty"`
+	LogLevel  string `json:"log_level,omitempty"`
+
+	// DedupThreshold notifies the same container and action at most this many times within
+	// DedupWindowSeconds (default 60), then suppresses the rest and sends one summary when the
+	// window closes. Zero disables deduplication.
+	DedupThreshold     int `json:"dedup_threshold,omitempty"`
+	DedupWindowSeconds int `json:"dedup_window_seconds,omitempty"`
+
+	// DedupKeyTemplate decides which notifications count as duplicates of each other, as a
+	// template over the same fields as Templates (e.g. "{{.Image}} {{.Action}}"). Empty keys by
+	// host, container and action.
+	DedupKeyTemplate string `json:"dedup_key_template,omitempty"`
+
+	// RequestTimeoutSeconds bounds each webhook request (default 10). ProxyURL sends them through a
+	// proxy; without it the HTTP_PROXY and HTTPS_PROXY environment variables apply.
+	RequestTimeoutSeconds int    `json:"request_timeout_seconds,omitempty"`
+	ProxyURL              string `json:"proxy_url,omitempty"`
+	// httpClient is the shared webhook client built by validateConfig.
+	httpClient *http.Client
+
+	// DockerHost connects to this daemon instead of DOCKER_HOST, e.g. "tcp://10.0.0.5:2376".
+	// DockerTLSCA, DockerTLSCert and DockerTLSKey are PEM file paths for a TLS connection.
+	DockerHost    string `json:"docker_host,omitempty"`
+	DockerTLSCA   string `json:"docker_tls_ca,omitempty"`
+	DockerTLSCert string `json:"docker_tls_cert,omitempty"`
+	DockerTLSKey  string `json:"docker_tls_key,omitempty"`
+	// Hosts watches several Docker daemons at once instead of the single one above. Notifications
+	// name the host they came from.
+	Hosts []DockerHostConfig `json:"hosts,omitempty"`
+	// DockerWaitSeconds is how long startup retries unreachable Docker daemons (default 60). Startup
+	// gives up only if no daemon answered in that time.
+	DockerWaitSeconds int `json:"docker_wait_seconds,omitempty"`
+
+	// SendStartupMessage sends an info notification on launch to confirm the webhook works.
+	SendStartupMessage bool `json:"send_startup_message,omitempty"`
+
+	// RateLimitPerSecond caps all outgoing webhook requests, allowing bursts of RateLimitBurst
+	// (default 1). Zero disables the limit. Up to RateLimitQueue notifications (default 1000) wait
+	// to be sent, further ones are dropped; a changed queue size applies after a restart.
+	RateLimitPerSecond float64 `json:"rate_limit_per_second,omitempty"`
+	RateLimitBurst     int     `json:"rate_limit_burst,omitempty"`
+	RateLimitQueue     int     `json:"rate_limit_queue,omitempty"`
+	// compiledTemplates holds Templates parsed by validateConfig.
+	compiledTemplates map[string]compiledTemplate
+	// dedupKey is DedupKeyTemplate parsed by validateConfig.
+	dedupKey *template.Template
 }
 
+// iconURL is used as both the webhook avatar and the embed author icon.
+const iconURL = "https://raw.githubusercontent.com/Lyzev/DockaCord/refs/heads/master/assets/docker-mark-blue.png"
+
+// appCtx is cancelled on shutdown so event streams and webhook retries stop promptly.
+var appCtx, stopApp = context.WithCancel(context.Background())
+
+// configFile is the path of the config file, relative to the working directory.
+const configFile = "config.json"
+
+// placeholderWebhook is written to a freshly created config.json and must be replaced by the user.
+const placeholderWebhook = "discord-webhook-url"
+
 // Default configuration
 var defaultConfig = Config{
-	Webhook: "discord-webhook-url",
+	Webhook: placeholderWebhook,
 	Error:   []string{"die"},
 	Warning: []string{"stop"},
 	Info:    []string{"start"},
 }
 
-// Compile actions into lookup maps for O(1) membership checks.
-var errorActions map[string]bool
-var warnActions map[string]bool
-var infoActions map[string]bool
-
-func init() {
-	// Initialize action maps once (if config is unmodified, these remain valid).
-	errorActions = make(map[string]bool)
-	warnActions = make(map[string]bool)
-	infoActions = make(map[string]bool)
+// ignoredActions are generated by interactive use of a container and are dropped before any other
+// processing unless they are explicitly listed at a level.
+var ignoredActions = map[string]bool{
+	string(events.ActionAttach): true,
+	string(events.ActionDetach): true,
+	string(events.ActionResize): true,
 }
 
+// actionLevels maps each configured action to its level for O(1) lookups. The map is never
+// mutated, a reload swaps in a new one so the event goroutine can read it without locking.
+var actionLevels atomic.Pointer[map[string]string]
+
 func main() {
-	cfg, err := loadConfig("config.json")
+	dumpMappingFlag := flag.Bool("dump-mapping", false, "print the effective action to level mapping and exit")
+	dumpFormat := flag.String("dump-format", "table", "format for -dump-mapping: table or json")
+	preflight := flag.Bool("preflight", false, "check the config, Docker daemon and webhook, then exit")
+	flag.BoolVar(&dryRunFlag, "dry-run", false, "print notifications as JSON instead of posting them")
+	flag.Parse()
+
+	if *preflight {
+		exitPreflight(configFile)
+	}
+
+	// Printing the mapping must not leave a config.json behind.
+	load := loadConfig
+	if *dumpMappingFlag {
+		load = readConfig
+	}
+	cfg, err := load(configFile)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := validateConfig(cfg); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	setupLogging(cfg)
+	outboundLimit.configure(cfg)
+	if len(configuredWebhooks(cfg)) == 0 {
+		slog.Warn("No webhook is configured, set webhook or webhooks in config.json or DOCKACORD_WEBHOOK")
+	}
 
 	// Populate the action maps from the config on startup.
 	populateActionMaps(cfg)
 
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-	if err != nil {
-		log.Fatalf("Failed to create Docker client: %v", err)
+	activeConfig.Store(cfg)
+	if cfg.PolicyPath != "" {
+		policy, err := loadPolicy(cfg.PolicyPath, cfg)
+		if err != nil {
+			fatal("Failed to load policy", "error", err)
+		}
+		activePolicy.Store(policy)
+	}
+
+	if *dumpMappingFlag {
+		if err := dumpMapping(os.Stdout, *dumpFormat); err != nil {
+			fatal("Failed to dump mapping", "error", err)
+		}
+		return
+	}
+	if err := validateWebhooks(cfg); errors.Is(err, errPlaceholderWebhook) && !cfg.RefusePlaceholderWebhook {
+		slog.Error(strings.Repeat("*", 68))
+		slog.Error("Webhook not configured", "error", err)
+		slog.Error(strings.Repeat("*", 68))
+	} else if err != nil {
+		fatal("Invalid webhook", "error", err)
+	}
+	if cfg.PolicyPath != "" {
+		go watchPolicy(cfg.PolicyPath)
 	}
-	log.Println("Docker client created")
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	hosts := dockerHosts(cfg)
+	clients := make(dockerClients, 0, len(hosts))
+	pingers := make([]daemonPinger, 0, len(hosts))
+	for _, host := range hosts {
+		cli, err := newDockerClient(host)
+		if err != nil {
+			fatal("Failed to create Docker client", hostAttrs(host.Name, "error", err)...)
+		}
+		clients = append(clients, cli)
+		pingers = append(pingers, cli)
+	}
+	if err := waitForDaemons(appCtx, hosts, pingers, cfg); err != nil {
+		fatal("Docker daemon unreachable", "error", err)
+	}
+	log.Printf("Docker client(s) created for %d host(s)", len(hosts))
+	inspector = clients
+	logReader = clients
+	health.expect(len(hosts))
+	log.Printf("Run ID: %s", runID)
+
+	// ctx only stops event intake, so deliveries can still finish after a shutdown signal.
+	ctx, stopEvents := context.WithCancel(appCtx)
+	defer stopEvents()
+
+	for addr, mux := range serveMuxes(cfg) {
+		srv := startHTTPServer(addr, mux)
+		defer stopHTTPServer(srv)
+	}
+	if cfg.HealthAddr != "" && cfg.HealthPingSeconds > 0 {
+		go watchDaemonHealth(ctx, hosts, pingers, cfg)
+	}
+	sender = newSendQueue(sendQueueSize(cfg))
+	go sender.run(appCtx)
+	startLogTails(ctx, cfg)
+	// A dry run must not deliver what earlier runs left behind.
+	if !cfg.DryRun {
+		replayPending(cfg)
+		if cfg.QueuePath != "" {
+			go outbox.run(appCtx, cfg)
+		}
+	}
 
-	// Filter only container events to reduce overhead
-	filterArgs := filters.NewArgs()
-	filterArgs.Add("type", "container")
-	msgs, errs := cli.Events(ctx, events.ListOptions{
-		Filters: filterArgs,
-	})
+	filterArgs := eventFilters(cfg)
+	msgs := make(chan hostEvent)
+	for i, host := range hosts {
+		go streamEvents(ctx, host.Name, subscriber(ctx, host.Name, clients[i], filterArgs), msgs, cfg.Backoff)
+	}
 
 	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(signalChan, handledSignals(cfg)...)
+
+	reloads := make(chan struct{}, 1)
+	go watchConfig(reloads, configFile, cfg.WebhooksDir)
 
-	go handleDockerEvents(msgs, errs, signalChan, cfg)
+	done := make(chan *Config)
+	go func() {
+		done <- handleDockerEvents(ctx, msgs, signalChan, reloads, cfg)
+	}()
 
 	log.Println("Listening for Docker container events and signals...")
-	select {}
+	if cfg.SendStartupMessage {
+		sendStartupMessage(cfg)
+	}
+	cfg = <-done
+	stopEvents()
+	drain(cfg, signalChan)
+	log.Println("Shutdown complete")
 }
 
-// handleDockerEvents processes Docker events and handles system signals.
-func handleDockerEvents(msgs <-chan events.Message, errs <-chan error, signalChan <-chan os.Signal, cfg *Config) {
+// handleDockerEvents processes the events of all hosts and handles system signals and config
+// changes. Reloads happen on this goroutine so events are never handled with a half-applied config.
+// It returns the config in effect when it stopped.
+func handleDockerEvents(ctx context.Context, msgs <-chan hostEvent, signalChan <-chan os.Signal, reloads <-chan struct{}, cfg *Config) *Config {
 	for {
 		select {
-		case event := <-msgs:
-			if event.Type == events.ContainerEventType {
-				handleEvent(event, cfg)
-			}
-		case err := <-errs:
-			if err != nil {
-				log.Printf("Error receiving Docker event: %v", err)
-			}
+		case <-ctx.Done():
+			return cfg
+		case msg := <-msgs:
+			handleEvent(msg.host, msg.event, cfg)
+		case <-reloads:
+			log.Printf("Config file %s changed, reloading config", configFile)
+			cfg = reloadConfig(cfg)
 		case sig := <-signalChan:
-			log.Printf("Received signal %v, shutting down", sig)
-			return
+			switch signalAction(sig, cfg) {
+			case signalExit:
+				log.Printf("Received signal %v, exiting immediately", sig)
+				os.Exit(0)
+			case signalReload:
+				log.Printf("Received signal %v, reloading config", sig)
+				cfg = reloadConfig(cfg)
+			case signalIgnore:
+				log.Printf("Received signal %v, ignoring", sig)
+			default:
+				log.Printf("Received signal %v, shutting down", sig)
+				return cfg
+			}
 		}
 	}
 }
 
-// handleEvent processes Docker events
-func handleEvent(event events.Message, cfg *Config) {
-	level := getEventLevel(string(event.Action))
-	if level == "" {
+// handleEvent processes a Docker event reported by the named host.
+func handleEvent(host string, event events.Message, cfg *Config) {
+	eventsReceived.inc(string(event.Type))
+	action := actionKey(event)
+	slog.Debug("Event received", "type", event.Type, "container", resourceName(event), "action", action)
+	receivedAt := time.Now()
+	// A deployment is detected from any labelled event, also from ones that are never notified.
+	if cfg.DeployWindowSeconds > 0 {
+		deployment.observe(event, cfg, receivedAt)
+	}
+	if ignoredActions[action] && getEventLevel(action) == "" {
+		return
+	}
+	n := notification{event: event, host: host, receivedAt: receivedAt, seq: eventSeq.Add(1)}
+	// The filters and container trackers only apply to container events.
+	if event.Type == events.ContainerEventType {
+		logTails.observe(event, cfg)
+		if muted(event, cfg) {
+			notificationsSuppressed.inc("muted")
+			return
+		}
+		if !containerAllowed(event, cfg) || !imageAllowed(event, cfg) || !serviceAllowed(event, cfg) {
+			notificationsSuppressed.inc("filtered")
+			return
+		}
+		if cfg.CrashLoopRestarts > 0 && crashLoops.observe(event, cfg, n.receivedAt) {
+			notificationsSuppressed.inc("crash_loop")
+			slog.Info("Suppressed for crash-looping container", "container", resourceName(event), "action", action)
+			return
+		}
+	}
+	n.level = getEventLevel(action)
+	if n.level == "" {
+		return
+	}
+	if n.level == "warning" && cfg.EscalateAfter > 0 {
+		if count, ok := escalations.record(n.host, event, cfg, n.receivedAt); ok {
+			n.level = "error"
+			n.notes = append(n.notes, fmt.Sprintf("Escalated from warning after %d repeats within %s", count, escalateWindow(cfg)))
+		}
+	}
+	if cfg.DeployWindowSeconds > 0 && n.level != "error" && deployment.active(n.receivedAt) {
+		notificationsSuppressed.inc("deployment")
+		slog.Info("Suppressed during deployment window", notificationAttrs(n)...)
+		return
+	}
+	if quiet(n.level, cfg, n.receivedAt) {
+		notificationsSuppressed.inc("quiet_hours")
+		slog.Info("Suppressed during quiet hours", notificationAttrs(n)...)
+		return
+	}
+	if n.level == "error" && cfg.ErrorBurstThreshold > 0 && burst.record(event, cfg, n.receivedAt) {
+		notificationsSuppressed.inc("error_burst")
+		slog.Info("Held for error burst summary", notificationAttrs(n)...)
+		return
+	}
+	if cfg.DedupThreshold > 0 && dedup.record(n, cfg) {
+		notificationsSuppressed.inc("dedup")
+		slog.Info("Suppressed duplicate", notificationAttrs(n)...)
 		return
 	}
 
-	log.Printf("Event: action=%s, level=%s", event.Action, level)
-	notifyDiscord(event, level, cfg.Webhook)
+	slog.Info("Event", append([]any{"seq", n.seq}, notificationAttrs(n)...)...)
+	recent.add(n)
+	if event.Type == events.ContainerEventType && shouldEnrich(action, cfg) {
+		n.fields = append(n.fields, enrichFields(event.Actor.ID, cfg)...)
+	}
+	switch {
+	case cfg.ImageAggregateSeconds > 0 && event.Actor.Attributes["image"] != "":
+		imageGroups.add(n, cfg)
+	case throttled(action, cfg):
+		throttle.hold(n, cfg)
+	default:
+		sender.submit(func() { notify(n, cfg) })
+	}
 }
 
-// getEventLevel determines the event level based on the action maps.
+// eventSeq numbers the events received by this process, starting at 1.
+var eventSeq atomic.Uint64
+
+// notification is an event on its way to Discord along with everything derived while handling it.
+type notification struct {
+	event events.Message
+	// host names the Docker host that reported the event when several are watched.
+	host       string
+	seq        uint64
+	level      string
+	receivedAt time.Time
+	notes      []string
+	fields     []embedField
+}
+
+// getEventLevel determines the event level based on the policy rules, then the action maps.
 func getEventLevel(action string) string {
-	if errorActions[action] {
-		return "error"
-	}
-	if warnActions[action] {
-		return "warning"
+	if rule := activePolicy.Load().match(action); rule != nil {
+		if rule.Level == "ignore" {
+			return ""
+		}
+		return rule.Level
 	}
-	if infoActions[action] {
-		return "info"
+	if levels := actionLevels.Load(); levels != nil {
+		return (*levels)[action]
 	}
 	return ""
 }
 
-// notifyDiscord sends a notification to Discord
-func notifyDiscord(event events.Message, level string, webhookURL string) {
-	formattedTimeR := fmt.Sprintf("<t:%d:R>", event.Time)
-	formattedTimeF := fmt.Sprintf("<t:%d:F>", event.Time)
-
-	payload := map[string]interface{}{
-		"username":   "DockaCord",
-		"avatar_url": "https://raw.githubusercontent.com/Lyzev/DockaCord/refs/heads/master/assets/docker-mark-blue.png",
-		"embeds": []map[string]interface{}{
-			{
-				"title":       fmt.Sprintf("Docker Event Notification - %s", strings.ToUpper(level)),
-				"url":         "https://lyzev.dev/",
-				"description": fmt.Sprintf("**Container**: `%s`\n**Action**: `%s`\n**At**: %s (%s)", event.Actor.Attributes["name"], event.Action, formattedTimeF, formattedTimeR),
-				"color":       getColor(level),
-				"footer": map[string]string{
-					"text": "© 2025 Lyzev.",
-				},
-				"author": map[string]string{
-					"name":     "Notification Bot",
-					"icon_url": "https://raw.githubusercontent.com/Lyzev/DockaCord/refs/heads/master/assets/docker-mark-blue.png",
-				},
-			},
+// notify renders the notification and sends it through the configured provider.
+func notify(n notification, cfg *Config) {
+	inFlight.start()
+	defer inFlight.done()
+	eventsNotified.inc(n.level)
+	webhookURL := eventWebhook(n, cfg)
+	rule := activePolicy.Load().match(actionKey(n.event))
+
+	at := eventTime(n.event)
+	if cfg.TimestampSource == "receive" {
+		at = n.receivedAt
+	}
+	tmpl := cfg.compiledTemplates[n.level]
+	title := renderTemplate(tmpl.title, n, at, fmt.Sprintf("Docker Event Notification - %s", strings.ToUpper(n.level)))
+	description := renderTemplate(tmpl.description, n, at, fmt.Sprintf("**%s**: `%s`\n**Action**: `%s`\n**At**: %s", resourceLabel(n.event), resourceName(n.event), n.event.Action, formatTime(at, cfg)))
+	if cfg.TimestampSource == "both" {
+		description += fmt.Sprintf("\n**Received**: %s", formatTime(n.receivedAt, cfg))
+	}
+	for _, note := range n.notes {
+		description += fmt.Sprintf("\n**Note**: %s", note)
+	}
+
+	var fields []embedField
+	if n.host != "" {
+		fields = append(fields, embedField{Name: "Docker Host", Value: n.host, Inline: true})
+	}
+	if n.event.Type == events.ContainerEventType {
+		fields = append(fields, containerFields(n.event)...)
+	}
+	if cfg.ShowEventType {
+		fields = append(fields, eventTypeFields(n.event)...)
+	}
+	fields = append(fields, swarmFields(n.event)...)
+	fields = append(fields, hostFields(n.event, cfg)...)
+	fields = append(fields, attributeFields(n.event, cfg)...)
+	fields = append(fields, n.fields...)
+	rendered, fields := renderFields(uniqueFields(fields), cfg)
+	description += rendered
+
+	embed := newEmbed(title, description, n.level, cfg)
+	if len(fields) > 0 {
+		embed["fields"] = fields
+	}
+	if rule != nil && rule.Color != nil && rule.Level == n.level {
+		embed["color"] = *rule.Color
+	}
+	footer := embed["footer"].(map[string]string)
+	if cfg.IncludeSequence {
+		footer["text"] += fmt.Sprintf(" • Seq %d", n.seq)
+	}
+	if cfg.FooterTimeFormat != "" {
+		footer["text"] += " • " + at.In(timeLocation(cfg)).Format(cfg.FooterTimeFormat)
+	}
+	if cfg.TimestampNano {
+		embed["timestamp"] = at.UTC().Format(time.RFC3339Nano)
+	}
+
+	mention := cfg.Mentions[n.level]
+	payload := notifierFor(cfg).Payload([]map[string]interface{}{embed}, mention)
+
+	if n.level == "info" && cfg.InfoMessageTTLSeconds > 0 {
+		if err := sendEphemeral(cfg, webhookURL, time.Duration(cfg.InfoMessageTTLSeconds)*time.Second, payload); err != nil {
+			slog.Error("Failed to send notification", append(notificationAttrs(n), "error", err)...)
+			return
+		}
+		slog.Info("Successfully sent notification", notificationAttrs(n)...)
+		return
+	}
+
+	// Batched messages have no content, so notifications with mentions are sent on their own.
+	if window := batchWindow(n.level, cfg); window > 0 && mention == "" {
+		batch.add(cfg, webhookURL, embed, window)
+		return
+	}
+	if _, err := deliver(cfg, webhookURL, payload); err != nil {
+		slog.Error("Failed to send notification", append(notificationAttrs(n), "error", err)...)
+		return
+	}
+	if cfg.QueuePath != "" && !cfg.DryRun {
+		slog.Info("Queued notification", notificationAttrs(n)...)
+		return
+	}
+	slog.Info("Successfully sent notification", notificationAttrs(n)...)
+}
+
+// eventTypeFields describes what kind of object the event concerns and its scope, when reported.
+func eventTypeFields(event events.Message) []embedField {
+	fields := []embedField{{Name: "Type", Value: string(event.Type), Inline: true}}
+	if event.Scope != "" {
+		fields = append(fields, embedField{Name: "Scope", Value: event.Scope, Inline: true})
+	}
+	return fields
+}
+
+// newEmbed builds a DockaCord-branded embed with the given title, description and level color.
+func newEmbed(title string, description string, level string, cfg *Config) map[string]interface{} {
+	footer := "© 2025 Lyzev."
+	if cfg.IncludeRunID {
+		footer += " • Run " + runID
+	}
+
+	embed := map[string]interface{}{
+		"title":       title,
+		"url":         "https://lyzev.dev/",
+		"description": description,
+		"color":       getColor(level, cfg),
+		"footer": map[string]string{
+			"text": footer,
 		},
 	}
+	if cfg.EmbedAuthorEnabled == nil || *cfg.EmbedAuthorEnabled {
+		embed["author"] = map[string]string{
+			"name":     "Notification Bot",
+			"icon_url": iconURL,
+		}
+	}
+	return embed
+}
+
+// sendEmbeds posts the embeds to the webhook as a single DockaCord message.
+func sendEmbeds(cfg *Config, webhookURL string, embeds ...map[string]interface{}) error {
+	_, err := deliver(cfg, webhookURL, newPayload(cfg, embeds))
+	return err
+}
+
+// newPayload wraps embeds in a webhook message of the configured provider.
+func newPayload(cfg *Config, embeds []map[string]interface{}) map[string]interface{} {
+	return notifierFor(cfg).Payload(embeds, "")
+}
+
+// deliver hands the payload to the persistent delivery queue when one is configured, and sends it
+// right away otherwise. Queued payloads have no response body.
+func deliver(cfg *Config, webhookURL string, payload interface{}) ([]byte, error) {
+	if cfg.QueuePath != "" && !cfg.DryRun {
+		return nil, outbox.enqueue(cfg, webhookURL, payload)
+	}
+	return send(cfg, webhookURL, payload)
+}
+
+// send posts the payload with retries, moving it to the dead-letter queue when every attempt
+// failed for a transient reason and draining the queue after a success when one is configured. It returns the response body.
+func send(cfg *Config, webhookURL string, payload interface{}) ([]byte, error) {
+	if cfg.DryRun {
+		return nil, printPayload(webhookURL, payload)
+	}
+	body, err := postWithRetry(cfg, webhookURL, payload)
+	if cfg.DeadLetterPath == "" {
+		return body, err
+	}
+	if err != nil {
+		// Requests the webhook rejected outright would fail again on replay.
+		if retryableWebhookError(err) {
+			dlq.push(cfg, webhookURL, payload)
+		}
+		return nil, err
+	}
+	go dlq.drain(cfg)
+	return body, nil
+}
+
+// postWithRetry posts the payload, retrying network errors, rate limits and server errors with
+// backoff until the attempts run out or the app shuts down.
+func postWithRetry(cfg *Config, webhookURL string, payload interface{}) ([]byte, error) {
+	inFlight.start()
+	defer inFlight.done()
+	maxAttempts := cfg.Backoff.maxAttempts()
+	for attempt := 1; ; attempt++ {
+		body, err := postWebhook(cfg, webhookURL, payload)
+		if err == nil || !retryableWebhookError(err) || attempt >= maxAttempts {
+			return body, err
+		}
+
+		delay := cfg.Backoff.wait(attempt)
+		if d := rateLimitDelay(err); d > 0 {
+			delay = d
+		}
+		slog.Warn("Webhook attempt failed, retrying", "attempt", attempt, "max_attempts", maxAttempts, "delay", delay, "error", err)
+		select {
+		case <-appCtx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+	}
+}
 
+// postWebhook marshals the payload and posts it to the webhook URL with any configured credentials.
+// It returns the response body, which Discord only fills when the URL asks it to wait.
+func postWebhook(cfg *Config, webhookURL string, payload interface{}) ([]byte, error) {
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Failed to marshal payload: %v", err)
-		return
+		return nil, fmt.Errorf("failed to marshal payload: %v", err)
 	}
 
 	if webhookURL == "" {
-		log.Println("Missing Discord webhook URL in config")
-		return
+		return nil, errors.New("missing Discord webhook URL in config")
 	}
 
-	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(payloadBytes))
+	req, err := http.NewRequestWithContext(appCtx, http.MethodPost, webhookURL, bytes.NewBuffer(payloadBytes))
 	if err != nil {
-		log.Printf("Failed to send webhook: %v", err)
-		return
+		return nil, fmt.Errorf("failed to create webhook request: %v", err)
+	}
+	if err := discordRateLimit.wait(appCtx); err != nil {
+		return nil, err
+	}
+	if err := outboundLimit.wait(appCtx); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setWebhookAuth(req, cfg)
+
+	start := time.Now()
+	resp, err := webhookClient(cfg).Do(req)
+	observeWebhook(start, resp, cfg)
+	if resp != nil {
+		slog.Debug("Webhook response", "status", resp.StatusCode, "duration", time.Since(start))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to send webhook: %w", err)
 	}
 	defer func(Body io.ReadCloser) {
 		if closeErr := Body.Close(); closeErr != nil {
-			log.Printf("Failed to close response body: %v", closeErr)
+			slog.Warn("Failed to close response body", "error", closeErr)
 		}
 	}(resp.Body)
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		log.Printf("Unexpected HTTP status: %d", resp.StatusCode)
-	} else {
-		log.Println("Successfully sent Discord notification")
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %v", err)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, &webhookStatusError{StatusCode: resp.StatusCode, RetryAfter: handleRateLimit(resp, body)}
 	}
+	if !successStatus(resp.StatusCode, cfg) {
+		return nil, &webhookStatusError{StatusCode: resp.StatusCode}
+	}
+	return body, nil
+}
+
+// successStatus reports whether the webhook response code counts as a successful delivery.
+func successStatus(code int, cfg *Config) bool {
+	if len(cfg.SuccessStatusCodes) == 0 {
+		return code == http.StatusOK || code == http.StatusNoContent
+	}
+	for _, c := range cfg.SuccessStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookStatusError is returned when the webhook answers with an unexpected HTTP status.
+// RetryAfter is set for rate-limited responses.
+type webhookStatusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *webhookStatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status: %d", e.StatusCode)
+}
+
+// eventTime returns the event time, using the nanosecond timestamp when the daemon provides one.
+func eventTime(event events.Message) time.Time {
+	if event.TimeNano != 0 {
+		return time.Unix(0, event.TimeNano)
+	}
+	return time.Unix(event.Time, 0)
+}
+
+// timeLocation returns the configured time zone, falling back to UTC for unvalidated configs.
+func timeLocation(cfg *Config) *time.Location {
+	if cfg.timeLocation == nil {
+		return time.UTC
+	}
+	return cfg.timeLocation
+}
+
+// defaultTimestampFormat is the layout of absolute timestamps when footer_time_format is unset.
+const defaultTimestampFormat = "2006-01-02 15:04:05.000 MST"
+
+// formatTime renders t in the configured timestamp style.
+func formatTime(t time.Time, cfg *Config) string {
+	if cfg.TimestampStyle == "" || cfg.TimestampStyle == "discord" {
+		return discordTime(t)
+	}
+	layout := cfg.FooterTimeFormat
+	if layout == "" {
+		layout = defaultTimestampFormat
+	}
+	absolute := t.In(timeLocation(cfg)).Format(layout)
+	if cfg.TimestampStyle == "both" {
+		return absolute + " • " + discordTime(t)
+	}
+	return absolute
+}
+
+// discordTime renders t as Discord's full and relative timestamp markup.
+func discordTime(t time.Time) string {
+	return fmt.Sprintf("<t:%d:F> (<t:%d:R>)", t.Unix(), t.Unix())
 }
 
 // getColor returns the color code for the given level.
-func getColor(level string) int {
+func getColor(level string, cfg *Config) int {
+	if custom, ok := cfg.Levels[level]; ok {
+		return custom.Color
+	}
 	switch level {
 	case "warning":
 		return 16776960
@@ -189,29 +910,39 @@ func getColor(level string) int {
 	}
 }
 
-// populateActionMaps moves action slices into maps to avoid repeated in-slice scans.
+// populateActionMaps moves the action lists of every level into a map to avoid repeated in-slice
+// scans. Any previously loaded actions are replaced atomically.
 func populateActionMaps(cfg *Config) {
-	for _, a := range cfg.Error {
-		errorActions[a] = true
-	}
-	for _, a := range cfg.Warning {
-		warnActions[a] = true
-	}
-	for _, a := range cfg.Info {
-		infoActions[a] = true
+	levels := make(map[string]string)
+	for level, actions := range levelActions(cfg) {
+		for _, a := range actions {
+			levels[a] = level
+		}
 	}
+	actionLevels.Store(&levels)
 }
 
-// loadConfig loads configuration from a file
+// loadConfig loads configuration like readConfig, first creating a default config.json for the user
+// to edit unless the environment provides the webhook.
 func loadConfig(filename string) (*Config, error) {
-	_, err := os.Stat(filename)
-	if os.IsNotExist(err) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) && !envConfigured() {
 		log.Println("Config file not found, creating default config.json")
 		defBytes, _ := json.MarshalIndent(defaultConfig, "", "  ")
 		if writeErr := os.WriteFile(filename, defBytes, 0644); writeErr != nil {
 			return nil, fmt.Errorf("failed to create default config: %v", writeErr)
 		}
-		return &defaultConfig, nil
+	}
+	return readConfig(filename)
+}
+
+// readConfig loads configuration from a file and overlays the environment variables and flags.
+// Without the file the defaults are used and nothing is written.
+func readConfig(filename string) (*Config, error) {
+	_, err := os.Stat(filename)
+	if os.IsNotExist(err) {
+		cfg := defaultConfig
+		applyOverrides(&cfg)
+		return &cfg, nil
 	} else if err != nil {
 		return nil, fmt.Errorf("cannot stat config file: %v", err)
 	}
@@ -225,5 +956,278 @@ func loadConfig(filename string) (*Config, error) {
 	if err := json.Unmarshal(configBytes, &cfg); err != nil {
 		return nil, fmt.Errorf("invalid JSON in config file: %v", err)
 	}
+	applyOverrides(&cfg)
+	if err := readSecretFile(cfg.WebhookUsernameFile, &cfg.WebhookUsername); err != nil {
+		return nil, err
+	}
+	if err := readSecretFile(cfg.WebhookPasswordFile, &cfg.WebhookPassword); err != nil {
+		return nil, err
+	}
+	if err := readWebhooksDir(&cfg); err != nil {
+		return nil, err
+	}
 	return &cfg, nil
 }
+
+// setWebhookAuth adds the configured basic auth credentials and extra headers to a webhook request.
+func setWebhookAuth(req *http.Request, cfg *Config) {
+	if cfg.WebhookUsername != "" || cfg.WebhookPassword != "" {
+		req.SetBasicAuth(cfg.WebhookUsername, cfg.WebhookPassword)
+	}
+	for name, value := range cfg.WebhookHeaders {
+		req.Header.Set(name, value)
+	}
+}
+
+// readSecretFile replaces *value with the trimmed contents of filename, if a filename is set.
+func readSecretFile(filename string, value *string) error {
+	if filename == "" {
+		return nil
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("cannot read secret file: %v", err)
+	}
+	*value = strings.TrimSpace(string(b))
+	return nil
+}
+
+// readWebhooksDir sets the webhooks from the files in WebhooksDir, if one is set. Hidden entries,
+// such as the ..data link of a Kubernetes secret volume, and directories are skipped.
+func readWebhooksDir(cfg *Config) error {
+	if cfg.WebhooksDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(cfg.WebhooksDir)
+	if err != nil {
+		return fmt.Errorf("cannot read webhooks directory: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		filename := filepath.Join(cfg.WebhooksDir, name)
+		if info, err := os.Stat(filename); err != nil || info.IsDir() {
+			continue
+		}
+		var webhook string
+		if err := readSecretFile(filename, &webhook); err != nil {
+			return err
+		}
+		switch {
+		case name == "default":
+			cfg.Webhook = webhook
+		case isLevel(name, cfg):
+			if cfg.Webhooks == nil {
+				cfg.Webhooks = make(map[string]string)
+			}
+			cfg.Webhooks[name] = webhook
+		default:
+			return fmt.Errorf("invalid file %q in webhooks directory: must be named after a level or default", name)
+		}
+	}
+	return nil
+}
+
+// webhookFor returns the webhook for the level, falling back to the default webhook.
+func webhookFor(level string, cfg *Config) string {
+	if webhook := cfg.Webhooks[level]; webhook != "" {
+		return webhook
+	}
+	return cfg.Webhook
+}
+
+// eventWebhook returns the webhook for a notification: the webhook of the matching policy rule,
+// then that of the event type, then that of the level.
+func eventWebhook(n notification, cfg *Config) string {
+	if rule := activePolicy.Load().match(actionKey(n.event)); rule != nil && rule.Webhook != "" {
+		return rule.Webhook
+	}
+	if webhook := cfg.TypeWebhooks[string(n.event.Type)]; webhook != "" {
+		return webhook
+	}
+	return webhookFor(n.level, cfg)
+}
+
+// configuredWebhooks returns the distinct non-empty webhooks of the config in a stable order.
+func configuredWebhooks(cfg *Config) []string {
+	webhooks := []string{}
+	seen := make(map[string]bool)
+	add := func(webhook string) {
+		if webhook != "" && !seen[webhook] {
+			seen[webhook] = true
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	addAll := func(routes map[string]string) {
+		keys := make([]string, 0, len(routes))
+		for key := range routes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			add(routes[key])
+		}
+	}
+	add(cfg.Webhook)
+	addAll(cfg.Webhooks)
+	addAll(cfg.TypeWebhooks)
+	return webhooks
+}
+
+// discordWebhookHosts are the hosts Discord serves webhooks from.
+var discordWebhookHosts = []string{"discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com"}
+
+// slackWebhookHost serves Slack incoming webhooks.
+const slackWebhookHost = "hooks.slack.com"
+
+// discordWebhookPath matches /api/webhooks/<id>/<token>, optionally with an API version.
+var discordWebhookPath = regexp.MustCompile(`^/api(/v\d+)?/webhooks/\d+/[\w-]+/?$`)
+
+// errPlaceholderWebhook is returned while the webhook is still the one written to a fresh config.json.
+var errPlaceholderWebhook = fmt.Errorf("the Discord webhook is still the placeholder %q: edit config.json and set a real webhook URL, no notifications can be delivered until then", placeholderWebhook)
+
+// validateWebhookURL returns an actionable error if the webhook is the placeholder, not an https URL,
+// or not a webhook URL of the configured provider. A host listed in AllowedWebhookHosts accepts any
+// path and plain http, for relays inside the network; with the list set, other hosts are rejected.
+func validateWebhookURL(webhookURL string, cfg *Config) error {
+	if webhookURL == placeholderWebhook {
+		return errPlaceholderWebhook
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid webhook URL: expected https://discord.com/api/webhooks/<id>/<token>")
+	}
+	host := strings.ToLower(u.Hostname())
+	if len(cfg.AllowedWebhookHosts) > 0 {
+		if !matchesAny(host, cfg.AllowedWebhookHosts) {
+			return fmt.Errorf("webhook host %q is not in allowed_webhook_hosts", u.Hostname())
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook URL %s must use https", redactWebhook(webhookURL))
+	}
+	if cfg.Provider == providerSlack {
+		if host != slackWebhookHost || !strings.HasPrefix(u.Path, "/services/") {
+			return fmt.Errorf("webhook URL %s is not a Slack webhook URL (https://hooks.slack.com/services/...), set allowed_webhook_hosts to use another host", redactWebhook(webhookURL))
+		}
+		return nil
+	}
+	if !slices.Contains(discordWebhookHosts, host) || !discordWebhookPath.MatchString(u.Path) {
+		return fmt.Errorf("webhook URL %s is not a Discord webhook URL (https://discord.com/api/webhooks/<id>/<token>), set allowed_webhook_hosts to use another host", redactWebhook(webhookURL))
+	}
+	return nil
+}
+
+// validateWebhooks checks every configured webhook URL. It is not part of validateConfig because
+// only sending needs a real webhook, printing the mapping works with the placeholder config.
+func validateWebhooks(cfg *Config) error {
+	for _, webhook := range configuredWebhooks(cfg) {
+		if err := validateWebhookURL(webhook, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateConfig checks the loaded configuration for values that would only fail later at runtime.
+func validateConfig(cfg *Config) error {
+	if cfg.WebUI && cfg.HealthAddr == "" {
+		return fmt.Errorf("web_ui requires health_addr")
+	}
+	for level, ms := range cfg.BatchWindowsMs {
+		if !isLevel(level, cfg) {
+			return fmt.Errorf("invalid batch_windows_ms level %q: must be error, warning, info or a configured level", level)
+		}
+		if ms < 0 {
+			return fmt.Errorf("invalid batch_windows_ms for %q: must not be negative", level)
+		}
+	}
+	patternLists := [][]string{cfg.IncludeNames, cfg.ExcludeNames, cfg.IncludeImages, cfg.ExcludeImages, cfg.AllowedWebhookHosts, cfg.IncludeServices}
+	for _, label := range append(append([]string{}, cfg.IncludeLabels...), cfg.ExcludeLabels...) {
+		if _, value, ok := strings.Cut(label, "="); ok {
+			patternLists = append(patternLists, []string{value})
+		}
+	}
+	for _, rule := range cfg.LogTail {
+		if _, err := regexp.Compile(rule.Match); err != nil {
+			return fmt.Errorf("invalid log_tail match %q: %v", rule.Match, err)
+		}
+		if rule.Level != "" && !isLevel(rule.Level, cfg) {
+			return fmt.Errorf("invalid log_tail level %q: must be error, warning, info or a configured level", rule.Level)
+		}
+		patternLists = append(patternLists, rule.Containers)
+	}
+	for _, patterns := range patternLists {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+		}
+	}
+	switch cfg.AttributeRenderMode {
+	case "", "fields", "table", "inline":
+	default:
+		return fmt.Errorf("invalid attribute_render_mode %q: must be fields, table or inline", cfg.AttributeRenderMode)
+	}
+	loc, err := time.LoadLocation(cfg.FooterTimeZone)
+	if err != nil {
+		return fmt.Errorf("invalid footer_time_zone %q: %v", cfg.FooterTimeZone, err)
+	}
+	cfg.timeLocation = loc
+	if err := validateHostFields(cfg); err != nil {
+		return err
+	}
+	if err := validateSignals(cfg); err != nil {
+		return err
+	}
+	if err := validateTypes(cfg); err != nil {
+		return err
+	}
+	if err := validateLogging(cfg); err != nil {
+		return err
+	}
+	if cfg.RateLimitPerSecond < 0 || cfg.RateLimitBurst < 0 || cfg.RateLimitQueue < 0 {
+		return fmt.Errorf("rate_limit_per_second, rate_limit_burst and rate_limit_queue must not be negative")
+	}
+	if err := validateProvider(cfg); err != nil {
+		return err
+	}
+	if err := validateMentions(cfg); err != nil {
+		return err
+	}
+	if err := validateLevels(cfg); err != nil {
+		return err
+	}
+	if err := validateQuietHours(cfg); err != nil {
+		return err
+	}
+	if err := compileTemplates(cfg); err != nil {
+		return err
+	}
+	if err := buildHTTPClient(cfg); err != nil {
+		return err
+	}
+	if err := validateHosts(cfg); err != nil {
+		return err
+	}
+	if cfg.DockerWaitSeconds < 0 {
+		return fmt.Errorf("docker_wait_seconds must not be negative")
+	}
+	if err := cfg.Backoff.validate(); err != nil {
+		return err
+	}
+	switch cfg.TimestampSource {
+	case "", "event", "receive", "both":
+	default:
+		return fmt.Errorf("invalid timestamp_source %q: must be event, receive or both", cfg.TimestampSource)
+	}
+	switch cfg.TimestampStyle {
+	case "", "discord", "absolute", "both":
+	default:
+		return fmt.Errorf("invalid timestamp_style %q: must be discord, absolute or both", cfg.TimestampStyle)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		cfg     Config
+		wantErr bool
+	}{
+		{"placeholder", placeholderWebhook, Config{}, true},
+		{"not a URL", "not a url", Config{}, true},
+		{"missing host", "https:///api/webhooks/1/token", Config{}, true},
+		{"plain http", "http://discord.com/api/webhooks/123/token", Config{}, true},
+		{"foreign host", "https://example.com/api/webhooks/123/token", Config{}, true},
+		{"wrong path", "https://discord.com/api/channels/123", Config{}, true},
+		{"valid", "https://discord.com/api/webhooks/123456789/abc-DEF_123", Config{}, false},
+		{"valid legacy host", "https://discordapp.com/api/webhooks/123456789/abc", Config{}, false},
+		{"allowed host", "https://relay.example.com/hook", Config{AllowedWebhookHosts: []string{"*.example.com"}}, false},
+		{"plain http on allowed host", "http://relay.example.com/hook", Config{AllowedWebhookHosts: []string{"*.example.com"}}, false},
+		{"plain http outside allowed hosts", "http://discord.com/api/webhooks/123/token", Config{AllowedWebhookHosts: []string{"*.example.com"}}, true},
+		{"unsupported scheme on allowed host", "ftp://relay.example.com/hook", Config{AllowedWebhookHosts: []string{"*.example.com"}}, true},
+		{"slack", "https://hooks.slack.com/services/T0/B0/x", Config{Provider: providerSlack}, false},
+		{"discord with slack provider", "https://discord.com/api/webhooks/123/token", Config{Provider: providerSlack}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookURL(tt.url, &tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateWebhookURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	at := time.Unix(0, 1700000000123456789)
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"discord tags by default", Config{}, "<t:1700000000:F> (<t:1700000000:R>)"},
+		{"absolute in UTC", Config{TimestampStyle: "absolute"}, "2023-11-14 22:13:20.123 UTC"},
+		{"absolute with layout and zone", Config{TimestampStyle: "absolute", FooterTimeFormat: time.RFC3339Nano, FooterTimeZone: "Europe/Berlin"}, "2023-11-14T23:13:20.123456789+01:00"},
+		{"both", Config{TimestampStyle: "both", FooterTimeFormat: "15:04"}, "22:13 • <t:1700000000:F> (<t:1700000000:R>)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateConfig(&tt.cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := formatTime(at, &tt.cfg); got != tt.want {
+				t.Fatalf("formatTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventWebhook(t *testing.T) {
+	cfg := &Config{
+		Webhook:      "default",
+		Webhooks:     map[string]string{"error": "errors"},
+		TypeWebhooks: map[string]string{"image": "ops"},
+	}
+	tests := []struct {
+		name      string
+		eventType events.Type
+		level     string
+		want      string
+	}{
+		{"default", events.ContainerEventType, "info", "default"},
+		{"level route", events.ContainerEventType, "error", "errors"},
+		{"type route", events.ImageEventType, "info", "ops"},
+		{"type route before level route", events.ImageEventType, "error", "ops"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := notification{event: events.Message{Type: tt.eventType, Action: "delete"}, level: tt.level}
+			if got := eventWebhook(n, cfg); got != tt.want {
+				t.Fatalf("eventWebhook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadWebhooksDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"default": "https://discord.com/api/webhooks/1/default\n",
+		"error":   "https://discord.com/api/webhooks/2/error",
+		"..data":  "ignored",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Webhook: "from-config", Webhooks: map[string]string{"info": "info-from-config"}, WebhooksDir: dir}
+	if err := readWebhooksDir(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Webhook != "https://discord.com/api/webhooks/1/default" {
+		t.Errorf("Webhook = %q, want the default file's webhook", cfg.Webhook)
+	}
+	want := map[string]string{"error": "https://discord.com/api/webhooks/2/error", "info": "info-from-config"}
+	if !maps.Equal(cfg.Webhooks, want) {
+		t.Errorf("Webhooks = %v, want %v", cfg.Webhooks, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ops"), []byte("https://discord.com/api/webhooks/3/ops"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := readWebhooksDir(cfg); err == nil {
+		t.Error("readWebhooksDir() accepted a file not named after a level")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// knownActions lists the container actions reported by the Docker daemon.
+var knownActions = []string{
+	"attach", "commit", "copy", "create", "destroy", "detach", "die", "exec_create", "exec_detach",
+	"exec_die", "exec_start", "export", "health_status", "kill", "oom", "pause", "prune", "rename",
+	"resize", "restart", "start", "stop", "top", "unpause", "update",
+}
+
+// effectiveMapping resolves the level of every known and configured action.
+// Actions that are not notified map to an empty level, or "ignored" for the interactive actions
+// that are dropped by default.
+func effectiveMapping() map[string]string {
+	mapping := make(map[string]string)
+	for _, action := range knownActions {
+		mapping[action] = getEventLevel(action)
+		if mapping[action] == "" && ignoredActions[action] {
+			mapping[action] = "ignored"
+		}
+	}
+	if levels := actionLevels.Load(); levels != nil {
+		for action := range *levels {
+			mapping[action] = getEventLevel(action)
+		}
+	}
+	return mapping
+}
+
+// dumpMapping writes the effective action→level mapping as an aligned table or as JSON.
+func dumpMapping(w io.Writer, format string) error {
+	mapping := effectiveMapping()
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(mapping)
+	case "table":
+		actions := make([]string, 0, len(mapping))
+		for action := range mapping {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "ACTION\tLEVEL")
+		for _, action := range actions {
+			level := mapping[action]
+			if level == "" {
+				level = "-"
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", action, level)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown mapping format %q: must be table or json", format)
+	}
+}
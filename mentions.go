@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// noMentions is the allowed_mentions object that stops Discord from pinging anyone.
+var noMentions = map[string]interface{}{"parse": []string{}}
+
+// mentionPattern matches a role mention <@&id> or a user mention <@id> or <@!id>.
+var mentionPattern = regexp.MustCompile(`<@([!&]?)(\d+)>`)
+
+// validateMentions rejects mention strings that contain anything but role and user mentions.
+func validateMentions(cfg *Config) error {
+	for level, mention := range cfg.Mentions {
+		rest := strings.TrimSpace(mentionPattern.ReplaceAllString(mention, ""))
+		if rest != "" || !mentionPattern.MatchString(mention) {
+			return fmt.Errorf("invalid mention %q for level %q: use role mentions <@&id> or user mentions <@id>", mention, level)
+		}
+	}
+	return nil
+}
+
+// addMentions puts the mentions in the message content, where unlike in embeds they notify, and
+// allows pings for exactly those roles and users.
+func addMentions(payload map[string]interface{}, mention string) {
+	roles, users := []string{}, []string{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(mention, -1) {
+		if m[1] == "&" {
+			roles = append(roles, m[2])
+		} else {
+			users = append(users, m[2])
+		}
+	}
+	payload["content"] = mention
+	payload["allowed_mentions"] = map[string]interface{}{"parse": []string{}, "roles": roles, "users": users}
+}
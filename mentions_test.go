@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestMentionPayloadAllowsOnlyConfiguredMentions(t *testing.T) {
+	payload := discordNotifier{}.Payload(nil, "<@&111> <@222> <@!333>")
+	if got := payload["content"]; got != "<@&111> <@222> <@!333>" {
+		t.Fatalf("content = %q, want the configured mentions", got)
+	}
+
+	// Round-trip through JSON to check what Discord receives.
+	raw, err := json.Marshal(payload["allowed_mentions"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var allowed struct {
+		Parse []string `json:"parse"`
+		Roles []string `json:"roles"`
+		Users []string `json:"users"`
+	}
+	if err := json.Unmarshal(raw, &allowed); err != nil {
+		t.Fatal(err)
+	}
+	if allowed.Parse == nil || len(allowed.Parse) != 0 {
+		t.Errorf("parse = %v, want an empty list so @everyone and other mentions never ping", allowed.Parse)
+	}
+	if want := []string{"111"}; !slices.Equal(allowed.Roles, want) {
+		t.Errorf("roles = %v, want %v", allowed.Roles, want)
+	}
+	if want := []string{"222", "333"}; !slices.Equal(allowed.Users, want) {
+		t.Errorf("users = %v, want %v", allowed.Users, want)
+	}
+}
+
+func TestPayloadWithoutMentionPingsNobody(t *testing.T) {
+	payload := discordNotifier{}.Payload(nil, "")
+	if _, ok := payload["content"]; ok {
+		t.Errorf("content = %q, want none", payload["content"])
+	}
+	raw, _ := json.Marshal(payload["allowed_mentions"])
+	if string(raw) != `{"parse":[]}` {
+		t.Errorf("allowed_mentions = %s, want {\"parse\":[]}", raw)
+	}
+}
+
+func TestValidateMentions(t *testing.T) {
+	tests := []struct {
+		mention string
+		wantErr bool
+	}{
+		{"<@&123>", false},
+		{"<@123> <@!456>", false},
+		{"@everyone", true},
+		{"<@&123> @here", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		err := validateMentions(&Config{Mentions: map[string]string{"error": tt.mention}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateMentions(%q) error = %v, wantErr %v", tt.mention, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// counterVec is a Prometheus counter partitioned by one label.
+type counterVec struct {
+	name   string
+	help   string
+	label  string
+	mu     sync.Mutex
+	values map[string]uint64
+}
+
+// inc adds one to the counter with the given label value.
+func (c *counterVec) inc(value string) {
+	c.add(value, 1)
+}
+
+// add adds n to the counter with the given label value.
+func (c *counterVec) add(value string, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values == nil {
+		c.values = make(map[string]uint64)
+	}
+	c.values[value] += uint64(n)
+}
+
+// write renders the counter in the Prometheus text format.
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if c.label == "" && len(c.values) == 0 {
+		_, _ = fmt.Fprintf(w, "%s 0\n", c.name)
+	}
+	values := make([]string, 0, len(c.values))
+	for value := range c.values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		if c.label == "" {
+			_, _ = fmt.Fprintf(w, "%s %d\n", c.name, c.values[value])
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", c.name, c.label, labelEscaper.Replace(value), c.values[value])
+	}
+}
+
+// histogram is a Prometheus histogram with fixed buckets.
+type histogram struct {
+	name    string
+	help    string
+	buckets []float64
+	mu      sync.Mutex
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// observe records one value.
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts == nil {
+		h.counts = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// write renders the histogram in the Prometheus text format.
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for i, bound := range h.buckets {
+		var n uint64
+		if h.counts != nil {
+			n = h.counts[i]
+		}
+		_, _ = fmt.Fprintf(w, "%s_bucket{le=\"%g\"} %d\n", h.name, bound, n)
+	}
+	_, _ = fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	_, _ = fmt.Fprintf(w, "%s_sum %g\n%s_count %d\n", h.name, h.sum, h.name, h.count)
+}
+
+// labelEscaper escapes label values as required by the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Metrics are always collected; they are only exposed when metrics_addr is set.
+var (
+	eventsReceived = &counterVec{
+		name:  "dockacord_events_received_total",
+		help:  "Docker events received, by event type.",
+		label: "type",
+	}
+	eventsNotified = &counterVec{
+		name:  "dockacord_events_notified_total",
+		help:  "Docker events notified, by level.",
+		label: "level",
+	}
+	notificationsSuppressed = &counterVec{
+		name:  "dockacord_suppressed_total",
+		help:  "Notifications suppressed or dropped before delivery, by reason.",
+		label: "reason",
+	}
+	webhookSuccesses = &counterVec{
+		name: "dockacord_webhook_successes_total",
+		help: "Webhook requests answered with a success status.",
+	}
+	webhookFailures = &counterVec{
+		name:  "dockacord_webhook_failures_total",
+		help:  "Webhook requests that failed, by reason.",
+		label: "reason",
+	}
+	webhookLatency = &histogram{
+		name:    "dockacord_webhook_request_duration_seconds",
+		help:    "Latency of webhook requests.",
+		buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}
+)
+
+// observeWebhook records the outcome and latency of one webhook request. A nil response means the
+// request failed before a response arrived.
+func observeWebhook(start time.Time, resp *http.Response, cfg *Config) {
+	webhookLatency.observe(time.Since(start).Seconds())
+	switch {
+	case resp == nil:
+		webhookFailures.inc("transport")
+	case resp.StatusCode == http.StatusTooManyRequests:
+		webhookFailures.inc("rate_limited")
+	case !successStatus(resp.StatusCode, cfg):
+		webhookFailures.inc(fmt.Sprintf("http_%d", resp.StatusCode))
+	default:
+		webhookSuccesses.inc("")
+	}
+}
+
+// registerMetrics adds the /metrics endpoint to mux.
+func registerMetrics(mux *http.ServeMux) {
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		for _, c := range []*counterVec{eventsReceived, eventsNotified, notificationsSuppressed, webhookSuccesses, webhookFailures} {
+			c.write(w)
+		}
+		webhookLatency.write(w)
+	})
+}
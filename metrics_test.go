@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	c := &counterVec{name: "dockacord_suppressed_total", help: "Suppressed.", label: "reason"}
+	c.inc("dedup")
+	c.inc("dedup")
+	c.add("delivery_queue_full", 3)
+
+	var sb strings.Builder
+	c.write(&sb)
+	want := `# HELP dockacord_suppressed_total Suppressed.
+# TYPE dockacord_suppressed_total counter
+dockacord_suppressed_total{reason="dedup"} 2
+dockacord_suppressed_total{reason="delivery_queue_full"} 3
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("write() = %q, want %q", got, want)
+	}
+}
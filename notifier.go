@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values of Config.Provider.
+const (
+	providerDiscord = "discord"
+	providerSlack   = "slack"
+)
+
+// Notifier turns messages into the webhook format of a chat service. Messages are built as Discord
+// embeds, the native format, and other providers translate them.
+type Notifier interface {
+	// Payload wraps the embeds in a webhook message, mentioning the given roles or users if the
+	// provider supports it.
+	Payload(embeds []map[string]interface{}, mention string) map[string]interface{}
+}
+
+// notifierFor returns the notifier of the configured provider.
+func notifierFor(cfg *Config) Notifier {
+	if cfg.Provider == providerSlack {
+		return slackNotifier{}
+	}
+	return discordNotifier{}
+}
+
+// validateProvider rejects unknown providers and options the provider cannot support.
+func validateProvider(cfg *Config) error {
+	switch cfg.Provider {
+	case "", providerDiscord:
+	case providerSlack:
+		if len(cfg.Mentions) > 0 {
+			return fmt.Errorf("mentions are not supported by the slack provider")
+		}
+		if cfg.InfoMessageTTLSeconds > 0 {
+			return fmt.Errorf("info_message_ttl_seconds is not supported by the slack provider")
+		}
+	default:
+		return fmt.Errorf("invalid provider %q: must be discord or slack", cfg.Provider)
+	}
+	return nil
+}
+
+// discordNotifier posts the embeds as they are.
+type discordNotifier struct{}
+
+// Payload wraps the embeds in a message sent as DockaCord. Mentions are disabled unless requested,
+// so nothing else in the message can ping the server.
+func (discordNotifier) Payload(embeds []map[string]interface{}, mention string) map[string]interface{} {
+	payload := map[string]interface{}{
+		"username":         "DockaCord",
+		"avatar_url":       iconURL,
+		"embeds":           embeds,
+		"allowed_mentions": noMentions,
+	}
+	if mention != "" {
+		addMentions(payload, mention)
+	}
+	return payload
+}
+
+// slackNotifier translates the embeds into Slack attachments with the same colors.
+type slackNotifier struct{}
+
+// Payload converts every embed into an attachment of a Slack incoming webhook message.
+func (slackNotifier) Payload(embeds []map[string]interface{}, _ string) map[string]interface{} {
+	attachments := make([]map[string]interface{}, 0, len(embeds))
+	for _, embed := range embeds {
+		attachment := map[string]interface{}{
+			"mrkdwn_in": []string{"text", "fields"},
+		}
+		if title, ok := embed["title"].(string); ok {
+			attachment["title"] = title
+			attachment["fallback"] = title
+		}
+		if link, ok := embed["url"].(string); ok {
+			attachment["title_link"] = link
+		}
+		if description, ok := embed["description"].(string); ok {
+			attachment["text"] = slackMarkdown(description)
+		}
+		if color, ok := embed["color"].(int); ok {
+			attachment["color"] = fmt.Sprintf("#%06x", color)
+		}
+		if footer, ok := embed["footer"].(map[string]string); ok {
+			attachment["footer"] = footer["text"]
+		}
+		if author, ok := embed["author"].(map[string]string); ok {
+			attachment["author_name"] = author["name"]
+			attachment["author_icon"] = author["icon_url"]
+		}
+		if fields, ok := embed["fields"].([]embedField); ok {
+			slackFields := make([]map[string]interface{}, 0, len(fields))
+			for _, f := range fields {
+				slackFields = append(slackFields, map[string]interface{}{"title": f.Name, "value": slackMarkdown(f.Value), "short": f.Inline})
+			}
+			attachment["fields"] = slackFields
+		}
+		attachments = append(attachments, attachment)
+	}
+	return map[string]interface{}{
+		"username":    "DockaCord",
+		"icon_url":    iconURL,
+		"attachments": attachments,
+	}
+}
+
+// discordTimestamp matches Discord's timestamp markup with an optional relative suffix, as written
+// by discordTime.
+var discordTimestamp = regexp.MustCompile(`<t:(\d+):[A-Za-z]>( \(<t:\d+:R>\))?`)
+
+// slackMarkdown converts the Discord markdown used in embeds to Slack's mrkdwn: bold uses single
+// asterisks and timestamps use Slack's date formatting.
+func slackMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "**", "*")
+	return discordTimestamp.ReplaceAllStringFunc(s, func(m string) string {
+		unix, _ := strconv.ParseInt(discordTimestamp.FindStringSubmatch(m)[1], 10, 64)
+		fallback := time.Unix(unix, 0).UTC().Format(time.RFC1123)
+		return fmt.Sprintf("<!date^%d^{date_long_pretty} {time_secs}|%s>", unix, fallback)
+	})
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path"
+	"sync/atomic"
+	"time"
+)
+
+// policyPollInterval is how often the policy file is checked for changes.
+const policyPollInterval = 5 * time.Second
+
+// Policy holds classification rules kept in a file separate from the main config, so the rules
+// can be owned and reloaded independently of connection settings.
+type Policy struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+// PolicyRule classifies actions matching the Action glob pattern. The first matching rule wins
+// over the action lists in the config.
+type PolicyRule struct {
+	Action string `json:"action"`
+	// Level is "error", "warning", "info", or "ignore" to drop matching actions.
+	Level string `json:"level"`
+	// Color and Webhook optionally override the level's color and the configured webhook.
+	Color   *int   `json:"color,omitempty"`
+	Webhook string `json:"webhook,omitempty"`
+}
+
+// activePolicy is swapped atomically whenever the policy file is reloaded.
+var activePolicy atomic.Pointer[Policy]
+
+// activeConfig is the config in effect, updated on every config reload. Policy webhooks are
+// validated against it and held notifications read it when their window closes.
+var activeConfig atomic.Pointer[Config]
+
+// currentConfig returns the config in effect, or cfg if none has been stored yet.
+func currentConfig(cfg *Config) *Config {
+	if active := activeConfig.Load(); active != nil {
+		return active
+	}
+	return cfg
+}
+
+// match returns the first rule matching the action, or nil.
+func (p *Policy) match(action string) *PolicyRule {
+	if p == nil {
+		return nil
+	}
+	for i := range p.Rules {
+		if ok, _ := path.Match(p.Rules[i].Action, action); ok {
+			return &p.Rules[i]
+		}
+	}
+	return nil
+}
+
+// loadPolicy reads a policy file and validates it against the config.
+func loadPolicy(filename string, cfg *Config) (*Policy, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read policy file: %v", err)
+	}
+	var p Policy
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("invalid JSON in policy file: %v", err)
+	}
+	if err := validatePolicy(&p, cfg); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// validatePolicy rejects invalid patterns, levels that are neither built in nor configured, and rule webhooks the config would not
+// accept, so a policy cannot send notifications and webhook credentials elsewhere.
+func validatePolicy(p *Policy, cfg *Config) error {
+	for _, rule := range p.Rules {
+		if _, err := path.Match(rule.Action, ""); err != nil {
+			return fmt.Errorf("invalid policy action pattern %q: %v", rule.Action, err)
+		}
+		if rule.Level != "ignore" && !isLevel(rule.Level, cfg) {
+			return fmt.Errorf("invalid policy level %q for %q: must be error, warning, info, ignore or a configured level", rule.Level, rule.Action)
+		}
+		if rule.Webhook != "" {
+			if err := validateWebhookURL(rule.Webhook, cfg); err != nil {
+				return fmt.Errorf("invalid webhook for policy rule %q: %v", rule.Action, err)
+			}
+		}
+	}
+	return nil
+}
+
+// watchPolicy reloads the policy file whenever its modification time changes. A policy that fails
+// to load is logged and the previous one stays active.
+func watchPolicy(filename string) {
+	var lastMod time.Time
+	if info, err := os.Stat(filename); err == nil {
+		lastMod = info.ModTime()
+	}
+	for range time.Tick(policyPollInterval) {
+		info, err := os.Stat(filename)
+		if err != nil || info.ModTime().Equal(lastMod) {
+			continue
+		}
+		lastMod = info.ModTime()
+
+		p, err := loadPolicy(filename, activeConfig.Load())
+		if err != nil {
+			slog.Error("Failed to reload policy, keeping the previous one", "error", err)
+			continue
+		}
+		activePolicy.Store(p)
+		log.Printf("Policy reloaded with %d rule(s)", len(p.Rules))
+	}
+}
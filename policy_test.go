@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPolicyValidatesRuleWebhooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook string
+		cfg     *Config
+		wantErr bool
+	}{
+		{"no webhook", "", &Config{}, false},
+		{"discord webhook", "https://discord.com/api/webhooks/1/token", &Config{}, false},
+		{"plain http", "http://discord.com/api/webhooks/1/token", &Config{}, true},
+		{"internal host", "https://internal.example/hook", &Config{}, true},
+		{"allowed host", "https://internal.example/hook", &Config{AllowedWebhookHosts: []string{"internal.example"}}, false},
+		{"host outside allow list", "https://discord.com/api/webhooks/1/token", &Config{AllowedWebhookHosts: []string{"internal.example"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "policy.json")
+			policy := `{"rules": [{"action": "die", "level": "error", "webhook": "` + tt.webhook + `"}]}`
+			if err := os.WriteFile(filename, []byte(policy), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadPolicy(filename, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePolicyLevels(t *testing.T) {
+	cfg := &Config{Levels: map[string]LevelConfig{"critical": {Color: 0x8B0000, Actions: []string{"oom"}}}}
+	tests := []struct {
+		level   string
+		wantErr bool
+	}{
+		{"error", false},
+		{"ignore", false},
+		{"critical", false},
+		{"fatal", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			p := &Policy{Rules: []PolicyRule{{Action: "die", Level: tt.level}}}
+			err := validatePolicy(p, cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validatePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// preflightTimeout bounds each network check performed by -preflight.
+const preflightTimeout = 10 * time.Second
+
+// runPreflight checks the config, the Docker daemon and the webhook, printing a checklist.
+// It reports whether every check passed.
+func runPreflight(filename string) bool {
+	passed := true
+	check := func(name string, err error) {
+		if err != nil {
+			passed = false
+			fmt.Printf("\033[31m✘\033[0m %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("\033[32m✔\033[0m %s\n", name)
+	}
+
+	// A preflight check must not create the config it is checking.
+	cfg, err := readConfig(filename)
+	if err == nil {
+		err = validateConfig(cfg)
+	}
+	if err == nil {
+		err = validateWebhooks(cfg)
+	}
+	check("Config valid", err)
+
+	for _, host := range dockerHosts(cfg) {
+		check("Docker daemon"+hostSuffix(host.Name)+" reachable", pingDaemon(host))
+	}
+
+	if cfg == nil {
+		check("Webhook reachable", fmt.Errorf("skipped, config could not be loaded"))
+	} else {
+		webhooks := configuredWebhooks(cfg)
+		if len(webhooks) == 0 {
+			check("Webhook reachable", fmt.Errorf("no webhook configured"))
+		}
+		for i, webhook := range webhooks {
+			check(fmt.Sprintf("Webhook %d/%d reachable", i+1, len(webhooks)), checkWebhook(cfg, webhook))
+		}
+	}
+	return passed
+}
+
+// pingDaemon creates a Docker client for the host and pings the daemon.
+func pingDaemon(host DockerHostConfig) error {
+	cli, err := newDockerClient(host)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cli.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+	_, err = cli.Ping(ctx)
+	return err
+}
+
+// checkWebhook sends a GET to the webhook, which Discord answers with the webhook object.
+func checkWebhook(cfg *Config, webhookURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, webhookURL, nil)
+	if err != nil {
+		return err
+	}
+	setWebhookAuth(req, cfg)
+	resp, err := webhookClient(cfg).Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	// Slack webhooks only accept POSTs, so any answer below 500 shows the webhook is reachable.
+	if cfg.Provider == providerSlack && resp.StatusCode < 500 {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected HTTP status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// exitPreflight runs the preflight checks and exits non-zero if any failed.
+func exitPreflight(filename string) {
+	if !runPreflight(filename) {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
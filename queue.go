@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultQueueMax bounds the delivery queue when no explicit size is configured.
+const defaultQueueMax = 1000
+
+// queuedMessage is a webhook request persisted for later delivery. ID identifies the entry within
+// its file, so it can be removed after delivery even if older entries were trimmed meanwhile.
+type queuedMessage struct {
+	ID      uint64          `json:"id,omitempty"`
+	Webhook string          `json:"webhook"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// deliveryQueue persists outgoing messages to disk and delivers them in order from a single
+// worker, so messages survive webhook outages and restarts.
+type deliveryQueue struct {
+	mu   sync.Mutex
+	wake chan struct{}
+}
+
+var outbox = deliveryQueue{wake: make(chan struct{}, 1)}
+
+// enqueue appends the payload to the queue file, dropping the oldest messages beyond the limit.
+func (q *deliveryQueue) enqueue(cfg *Config, webhookURL string, payload interface{}) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %v", err)
+	}
+
+	q.mu.Lock()
+	messages, err := readMessageFile(cfg.QueuePath)
+	if err == nil {
+		messages = append(messages, queuedMessage{ID: nextMessageID(messages), Webhook: webhookURL, Payload: raw})
+		limit := cfg.QueueMax
+		if limit <= 0 {
+			limit = defaultQueueMax
+		}
+		if dropped := len(messages) - limit; dropped > 0 {
+			notificationsSuppressed.add("delivery_queue_full", dropped)
+			slog.Warn("Delivery queue full, dropping oldest notifications", "dropped", dropped)
+			messages = messages[dropped:]
+		}
+		err = writeMessageFile(cfg.QueuePath, messages)
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to queue notification: %v", err)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// run delivers queued messages, including any left over from a previous run, until ctx is
+// cancelled. Failed deliveries are retried with backoff; messages the webhook rejects outright
+// are dropped so they cannot block the queue. Errors reading or updating the queue file are
+// retried as well, so the worker never stops while notifications are being queued.
+func (q *deliveryQueue) run(ctx context.Context, cfg *Config) {
+	attempt := 0
+	var sent *queuedMessage
+	retry := func(msg string, err error) bool {
+		attempt++
+		delay := cfg.Backoff.wait(attempt)
+		slog.Error(msg+", retrying", "delay", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+			return true
+		}
+	}
+
+	for {
+		// A message is removed by ID rather than position, as enqueue may have trimmed the queue
+		// while it was being delivered.
+		if sent != nil {
+			q.mu.Lock()
+			messages, err := readMessageFile(cfg.QueuePath)
+			if err == nil {
+				err = writeMessageFile(cfg.QueuePath, withoutMessage(messages, *sent))
+			}
+			q.mu.Unlock()
+			if err != nil {
+				if !retry("Failed to update delivery queue", err) {
+					return
+				}
+				continue
+			}
+			sent = nil
+			attempt = 0
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		q.mu.Lock()
+		messages, err := readMessageFile(cfg.QueuePath)
+		q.mu.Unlock()
+		if err != nil {
+			if !retry("Failed to read delivery queue", err) {
+				return
+			}
+			continue
+		}
+
+		if len(messages) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+				continue
+			}
+		}
+
+		_, err = postWebhook(cfg, messages[0].Webhook, messages[0].Payload)
+		if err != nil && retryableWebhookError(err) {
+			// A request cut short by shutdown was never answered, so it stays queued for the next run.
+			if ctx.Err() != nil {
+				return
+			}
+			attempt++
+			delay := cfg.Backoff.wait(attempt)
+			if d := rateLimitDelay(err); d > 0 {
+				delay = d
+			}
+			slog.Warn("Queued delivery failed, retrying", "pending", len(messages), "delay", delay, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+				continue
+			}
+		}
+		if err != nil {
+			slog.Error("Dropping queued notification rejected by webhook", "error", err)
+		} else {
+			slog.Info("Delivered queued notification")
+		}
+		attempt = 0
+		sent = &messages[0]
+	}
+}
+
+// nextMessageID returns an ID greater than that of every message in the file.
+func nextMessageID(messages []queuedMessage) uint64 {
+	var id uint64
+	for _, message := range messages {
+		id = max(id, message.ID)
+	}
+	return id + 1
+}
+
+// withoutMessage removes the first entry matching the delivered message. Entries written before
+// messages had IDs are matched by their content.
+func withoutMessage(messages []queuedMessage, sent queuedMessage) []queuedMessage {
+	for i, message := range messages {
+		if message.ID == sent.ID && message.Webhook == sent.Webhook && bytes.Equal(message.Payload, sent.Payload) {
+			return append(messages[:i:i], messages[i+1:]...)
+		}
+	}
+	return messages
+}
+
+// retryableWebhookError reports whether a failed request may succeed when repeated: transport
+// errors, rate limits, server errors and requests cancelled before they were sent are retried,
+// other client errors are not.
+func retryableWebhookError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var statusErr *webhookStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode < 400 || statusErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// readMessageFile loads all messages from a JSON-lines queue file. A missing file is an empty queue.
+// Corrupt lines are skipped so a single damaged entry cannot block delivery of the rest.
+func readMessageFile(filename string) ([]queuedMessage, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var messages []queuedMessage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var message queuedMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			slog.Warn("Skipping corrupt queue entry", "file", filename, "error", err)
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return messages, scanner.Err()
+}
+
+// writeMessageFile atomically replaces a queue file with the given messages. The new file is synced
+// before it replaces the old one, so a crash leaves either the old or the new queue on disk.
+func writeMessageFile(filename string, messages []queuedMessage) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, message := range messages {
+		if err := enc.Encode(message); err != nil {
+			return err
+		}
+	}
+
+	tmp := filename + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// replayPending delivers notifications left over from a previous run before live monitoring
+// starts. The dead-letter queue is replayed in order until an entry fails; the delivery queue is
+// worked off by its worker ahead of any new notification, which is appended behind it.
+func replayPending(cfg *Config) {
+	if cfg.QueuePath != "" {
+		if messages, err := readMessageFile(cfg.QueuePath); err == nil && len(messages) > 0 {
+			slog.Info("Resuming delivery of queued notifications", "count", len(messages))
+		}
+	}
+	if cfg.DeadLetterPath != "" {
+		if letters, err := readMessageFile(cfg.DeadLetterPath); err == nil && len(letters) > 0 {
+			slog.Info("Replaying notifications from the dead-letter queue", "count", len(letters))
+			dlq.drain(cfg)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeliveryQueueKeepsEntriesTrimmedDuringDelivery(t *testing.T) {
+	started := make(chan struct{})
+	proceed := make(chan struct{})
+	var mu sync.Mutex
+	var received []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Content string `json:"content"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received = append(received, payload.Content)
+		first := len(received) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-proceed
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{QueuePath: filepath.Join(t.TempDir(), "queue.jsonl"), QueueMax: 2}
+	q := deliveryQueue{wake: make(chan struct{}, 1)}
+	for _, content := range []string{"a", "b"} {
+		if err := q.enqueue(cfg, srv.URL, map[string]string{"content": content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.run(ctx, cfg)
+
+	<-started
+	// "a" is being delivered; queueing "c" trims it from the full queue.
+	if err := q.enqueue(cfg, srv.URL, map[string]string{"content": "c"}); err != nil {
+		t.Fatal(err)
+	}
+	close(proceed)
+
+	want := []string{"a", "b", "c"}
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := slices.Equal(received, want)
+		mu.Unlock()
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	t.Fatalf("received %v, want %v", received, want)
+}
+
+func TestWithoutMessage(t *testing.T) {
+	messages := []queuedMessage{
+		{ID: 2, Webhook: "w", Payload: []byte(`{}`)},
+		{ID: 3, Webhook: "w", Payload: []byte(`{}`)},
+	}
+	if got := withoutMessage(messages, queuedMessage{ID: 1, Webhook: "w", Payload: []byte(`{}`)}); len(got) != 2 {
+		t.Fatalf("removing a trimmed message left %d entries, want 2", len(got))
+	}
+	got := withoutMessage(messages, messages[1])
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("withoutMessage() = %v, want only ID 2", got)
+	}
+}
+
+func TestDeliveryQueueRemovesMessageDeliveredDuringShutdown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Shutdown begins while the webhook is answering.
+		cancel()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{QueuePath: filepath.Join(t.TempDir(), "queue.jsonl")}
+	q := deliveryQueue{wake: make(chan struct{}, 1)}
+	if err := q.enqueue(cfg, srv.URL, map[string]string{"content": "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.run(ctx, cfg)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run() did not return after shutdown")
+	}
+
+	messages, err := readMessageFile(cfg.QueuePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 0 {
+		t.Fatalf("queue holds %d message(s) after delivery, want 0", len(messages))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// clockLayout is the format of quiet hours start and end times.
+const clockLayout = "15:04"
+
+// QuietHours suppresses notifications of the listed levels between Start and End ("HH:MM") in
+// TimeZone (IANA name, default UTC). A window whose end is before its start wraps across midnight.
+// Levels defaults to info and warning; errors are never suppressed.
+type QuietHours struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	TimeZone string   `json:"time_zone,omitempty"`
+	Levels   []string `json:"levels,omitempty"`
+
+	// from, until and location are Start, End and TimeZone resolved by validateQuietHours.
+	from, until int
+	location    *time.Location
+}
+
+// defaultQuietLevels are the levels suppressed by a quiet hours window without explicit levels.
+var defaultQuietLevels = []string{"info", "warning"}
+
+// suppresses reports whether the window drops a notification of the given level at now. A window
+// that was not resolved by validateQuietHours suppresses nothing.
+func (q QuietHours) suppresses(level string, now time.Time) bool {
+	levels := q.Levels
+	if len(levels) == 0 {
+		levels = defaultQuietLevels
+	}
+	if q.location == nil || level == "error" || !slices.Contains(levels, level) {
+		return false
+	}
+
+	local := now.In(q.location)
+	minute := local.Hour()*60 + local.Minute()
+	if q.from <= q.until {
+		return minute >= q.from && minute < q.until
+	}
+	return minute >= q.from || minute < q.until
+}
+
+// quiet reports whether any quiet hours window drops a notification of the given level at now.
+func quiet(level string, cfg *Config, now time.Time) bool {
+	for _, window := range cfg.QuietHours {
+		if window.suppresses(level, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateQuietHours rejects windows with malformed times, unknown time zones, unknown levels or
+// the error level, and resolves the times and time zone of valid windows.
+func validateQuietHours(cfg *Config) error {
+	for i := range cfg.QuietHours {
+		window := &cfg.QuietHours[i]
+		start, err := time.Parse(clockLayout, window.Start)
+		if err != nil {
+			return fmt.Errorf("invalid quiet_hours[%d] start %q: must be HH:MM", i, window.Start)
+		}
+		end, err := time.Parse(clockLayout, window.End)
+		if err != nil {
+			return fmt.Errorf("invalid quiet_hours[%d] end %q: must be HH:MM", i, window.End)
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("invalid quiet_hours[%d]: start and end must differ", i)
+		}
+		loc, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return fmt.Errorf("invalid quiet_hours[%d] time_zone %q: %v", i, window.TimeZone, err)
+		}
+		for _, level := range window.Levels {
+			if level == "error" {
+				return fmt.Errorf("invalid quiet_hours[%d]: errors cannot be suppressed", i)
+			}
+			if !isLevel(level, cfg) {
+				return fmt.Errorf("invalid quiet_hours[%d] level %q: must be warning, info or a configured level", i, level)
+			}
+		}
+		window.from = start.Hour()*60 + start.Minute()
+		window.until = end.Hour()*60 + end.Minute()
+		window.location = loc
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursSuppresses(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available:", err)
+	}
+	resolve := func(window QuietHours) QuietHours {
+		cfg := &Config{QuietHours: []QuietHours{window}}
+		if err := validateQuietHours(cfg); err != nil {
+			t.Fatal(err)
+		}
+		return cfg.QuietHours[0]
+	}
+	overnight := resolve(QuietHours{Start: "22:00", End: "07:00", TimeZone: "Europe/Berlin"})
+	daytime := resolve(QuietHours{Start: "09:00", End: "17:00", Levels: []string{"info"}})
+	at := func(hour, minute int, loc *time.Location) time.Time {
+		return time.Date(2026, time.January, 15, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name   string
+		window QuietHours
+		level  string
+		now    time.Time
+		want   bool
+	}{
+		{"in window before midnight", overnight, "info", at(23, 30, berlin), true},
+		{"in window after midnight", overnight, "warning", at(3, 0, berlin), true},
+		{"at start", overnight, "info", at(22, 0, berlin), true},
+		{"at end", overnight, "info", at(7, 0, berlin), false},
+		{"out of window", overnight, "info", at(12, 0, berlin), false},
+		{"errors always pass", overnight, "error", at(23, 30, berlin), false},
+		{"zone applied", overnight, "info", at(21, 30, time.UTC), true},
+		{"other level", overnight, "deploy", at(23, 30, berlin), false},
+		{"daytime in window", daytime, "info", at(10, 0, time.UTC), true},
+		{"daytime out of window", daytime, "info", at(18, 0, time.UTC), false},
+		{"daytime level not listed", daytime, "warning", at(10, 0, time.UTC), false},
+		{"not validated", QuietHours{Start: "09:00", End: "17:00"}, "info", at(10, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.suppresses(tt.level, tt.now); got != tt.want {
+				t.Fatalf("suppresses(%q, %s) = %v, want %v", tt.level, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQuietHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  QuietHours
+		wantErr bool
+	}{
+		{"valid", QuietHours{Start: "22:00", End: "07:00"}, false},
+		{"bad start", QuietHours{Start: "25:00", End: "07:00"}, true},
+		{"bad end", QuietHours{Start: "22:00", End: "7"}, true},
+		{"empty window", QuietHours{Start: "22:00", End: "22:00"}, true},
+		{"unknown zone", QuietHours{Start: "22:00", End: "07:00", TimeZone: "Mars/Olympus"}, true},
+		{"error level", QuietHours{Start: "22:00", End: "07:00", Levels: []string{"error"}}, true},
+		{"unknown level", QuietHours{Start: "22:00", End: "07:00", Levels: []string{"debug"}}, true},
+		{"custom level", QuietHours{Start: "22:00", End: "07:00", Levels: []string{"deploy"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{QuietHours: []QuietHours{tt.window}, Levels: map[string]LevelConfig{"deploy": {}}}
+			err := validateQuietHours(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateQuietHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitGate holds back every webhook request until Discord's rate limit has reset, so
+// concurrent notifications wait together instead of each hitting another 429.
+type rateLimitGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+var discordRateLimit rateLimitGate
+
+// block stops requests from being sent for the given duration.
+func (g *rateLimitGate) block(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if until := time.Now().Add(d); until.After(g.until) {
+		g.until = until
+	}
+}
+
+// wait sleeps until the rate limit has reset or ctx is cancelled.
+func (g *rateLimitGate) wait(ctx context.Context) error {
+	g.mu.Lock()
+	d := time.Until(g.until)
+	g.mu.Unlock()
+	if d <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// retryAfter reads how long Discord asks us to wait from a 429 response, preferring the JSON body's
+// retry_after over the Retry-After header. Both are in seconds and may be fractional.
+func retryAfter(resp *http.Response, body []byte) time.Duration {
+	var rateLimited struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(body, &rateLimited); err == nil && rateLimited.RetryAfter > 0 {
+		return time.Duration(rateLimited.RetryAfter * float64(time.Second))
+	}
+	if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
+}
+
+// handleRateLimit blocks all webhook requests for the duration requested by a 429 response and
+// returns that duration.
+func handleRateLimit(resp *http.Response, body []byte) time.Duration {
+	d := retryAfter(resp, body)
+	if d > 0 {
+		log.Printf("Rate limited by Discord, holding webhook requests for %s", d)
+		discordRateLimit.block(d)
+	}
+	return d
+}
+
+// rateLimitDelay returns the wait requested by a rate-limited response, or zero for other errors.
+func rateLimitDelay(err error) time.Duration {
+	var statusErr *webhookStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
+
+// tokenBucket caps the rate of all outgoing webhook requests. Requests beyond the burst wait for
+// their turn in order; notifications queue in the sender in front of it.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+var outboundLimit tokenBucket
+
+// configure applies the configured rate and burst. A zero rate disables the limiter.
+func (b *tokenBucket) configure(cfg *Config) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = cfg.RateLimitPerSecond
+	b.burst = float64(cfg.RateLimitBurst)
+	if b.burst < 1 {
+		b.burst = 1
+	}
+	b.tokens = min(b.tokens, b.burst)
+	if b.last.IsZero() {
+		b.tokens = b.burst
+		b.last = time.Now()
+	}
+}
+
+// wait takes a token, sleeping until one is available or ctx is cancelled. Each waiting request
+// reserves its token up front, so waiters are released one interval apart in arrival order.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	if b.rate <= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		b.mu.Unlock()
+		return nil
+	}
+	b.tokens--
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPostWithRetryWaitsOutRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.05, "global": false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Backoff: BackoffConfig{BaseMs: 1, MaxAttempts: 3}}
+	if _, err := postWithRetry(cfg, srv.URL, map[string]string{"content": "hello"}); err != nil {
+		t.Fatalf("postWithRetry() error = %v, want delivery after the rate limit", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("server got %d requests, want 2", got)
+	}
+}
+
+func TestRetryableWebhookError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &webhookStatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", &webhookStatusError{StatusCode: http.StatusBadGateway}, true},
+		{"rejected", &webhookStatusError{StatusCode: http.StatusBadRequest}, false},
+		{"cancelled before sending", context.Canceled, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryableWebhookError(tt.err); got != tt.want {
+				t.Fatalf("retryableWebhookError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboundLimitCapsSendRate(t *testing.T) {
+	const rate, sends = 20, 10
+	outboundLimit.configure(&Config{RateLimitPerSecond: rate, RateLimitBurst: 1})
+	defer outboundLimit.configure(&Config{})
+
+	var mu sync.Mutex
+	var times []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := newSendQueue(sends)
+	go q.run(ctx)
+	cfg := &Config{}
+	var wg sync.WaitGroup
+	for i := 0; i < sends; i++ {
+		wg.Add(1)
+		if !q.submit(func() {
+			defer wg.Done()
+			_, _ = postWebhook(cfg, srv.URL, map[string]string{"content": "hello"})
+		}) {
+			t.Fatalf("submit() dropped notification %d with room in the queue", i)
+		}
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != sends {
+		t.Fatalf("server got %d requests, want %d", len(times), sends)
+	}
+	// With a burst of one, request n may be sent no earlier than n intervals after the first.
+	interval := time.Second / rate
+	for n := 1; n < len(times); n++ {
+		if gap := times[n].Sub(times[0]); gap < time.Duration(n)*interval-5*time.Millisecond {
+			t.Fatalf("request %d sent %s after the first, want at least %s", n, gap, time.Duration(n)*interval)
+		}
+	}
+}
+
+func TestSendQueueDropsWhenFull(t *testing.T) {
+	q := newSendQueue(1)
+	if !q.submit(func() {}) {
+		t.Fatal("submit() dropped a notification with room in the queue")
+	}
+	if q.submit(func() {}) {
+		t.Fatal("submit() queued a notification beyond the queue size")
+	}
+	// Release the queued send so later tests see nothing in flight.
+	<-q.jobs
+	inFlight.done()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// recentEventLimit is how many notified events the web UI and /recent keep in memory.
+const recentEventLimit = 200
+
+// recentEvent is a notified event as shown by /recent and the web UI.
+type recentEvent struct {
+	Time      time.Time `json:"time"`
+	Host      string    `json:"host,omitempty"`
+	Type      string    `json:"type"`
+	Action    string    `json:"action"`
+	Container string    `json:"container"`
+	Level     string    `json:"level"`
+}
+
+// recentBuffer is a ring buffer of the latest notified events that live viewers can subscribe to.
+type recentBuffer struct {
+	mu          sync.Mutex
+	events      []recentEvent
+	next        int
+	subscribers map[chan recentEvent]bool
+}
+
+var recent = recentBuffer{subscribers: make(map[chan recentEvent]bool)}
+
+// add stores the notification, replacing the oldest event once the buffer is full, and passes it
+// on to every subscriber. A subscriber that is not keeping up misses the event.
+func (b *recentBuffer) add(n notification) {
+	e := recentEvent{
+		Time:      n.receivedAt,
+		Host:      n.host,
+		Type:      string(n.event.Type),
+		Action:    string(n.event.Action),
+		Container: resourceName(n.event),
+		Level:     n.level,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.events) < recentEventLimit {
+		b.events = append(b.events, e)
+	} else {
+		b.events[b.next] = e
+	}
+	b.next = (b.next + 1) % recentEventLimit
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// snapshotLocked returns the buffered events from oldest to newest. b.mu must be held.
+func (b *recentBuffer) snapshotLocked() []recentEvent {
+	if len(b.events) < recentEventLimit {
+		return append([]recentEvent(nil), b.events...)
+	}
+	return append(append([]recentEvent(nil), b.events[b.next:]...), b.events[:b.next]...)
+}
+
+// snapshot returns the buffered events from oldest to newest.
+func (b *recentBuffer) snapshot() []recentEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.snapshotLocked()
+}
+
+// subscribe returns the buffered events and a channel receiving every event added afterwards,
+// so a viewer misses nothing in between. cancel must be called once the viewer is gone.
+func (b *recentBuffer) subscribe() (backlog []recentEvent, events <-chan recentEvent, cancel func()) {
+	ch := make(chan recentEvent, recentEventLimit)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[ch] = true
+	return b.snapshotLocked(), ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
+
+// registerWebUI adds the /recent endpoint, the live event stream and the web UI to mux.
+func registerWebUI(mux *http.ServeMux) {
+	mux.HandleFunc("/recent", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(recent.snapshot())
+	})
+	mux.HandleFunc("/ui/events", streamRecentEvents)
+	mux.HandleFunc("/ui", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(webUIPage))
+	})
+}
+
+// streamRecentEvents sends the buffered events and then every new one as server-sent events until
+// the viewer disconnects or DockaCord shuts down.
+func streamRecentEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	backlog, events, cancel := recent.subscribe()
+	defer cancel()
+	send := func(e recentEvent) bool {
+		data, _ := json.Marshal(e)
+		_, err := fmt.Fprintf(w, "data: %s\n\n", data)
+		return err == nil
+	}
+	for _, e := range backlog {
+		if !send(e) {
+			return
+		}
+	}
+	flusher.Flush()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-appCtx.Done():
+			return
+		case e := <-events:
+			if !send(e) {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// webUIPage lists the recent events and adds new ones as they arrive. Values are inserted as text,
+// never as HTML, since container names and actions come from the Docker daemon.
+const webUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>DockaCord</title>
+<style>
+body { font-family: sans-serif; margin: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; }
+tr.error td.level { color: #c0392b; }
+tr.warning td.level { color: #d68910; }
+tr.info td.level { color: #2471a3; }
+</style>
+</head>
+<body>
+<h1>DockaCord events</h1>
+<p>
+<label>Level <select id="level"><option value="">all</option><option>error</option><option>warning</option><option>info</option></select></label>
+<label>Container <input id="container" placeholder="name contains"></label>
+</p>
+<table>
+<thead><tr><th>Time</th><th>Host</th><th>Type</th><th>Container</th><th>Action</th><th>Level</th></tr></thead>
+<tbody id="events"></tbody>
+</table>
+<script>
+const rows = document.getElementById("events");
+const level = document.getElementById("level");
+const container = document.getElementById("container");
+
+function visible(row) {
+  return (!level.value || row.dataset.level === level.value) &&
+    row.dataset.container.includes(container.value);
+}
+
+function filter() {
+  for (const row of rows.children) {
+    row.hidden = !visible(row);
+  }
+}
+
+level.addEventListener("change", filter);
+container.addEventListener("input", filter);
+
+const source = new EventSource("ui/events");
+// Every connection starts with the buffered events, so a reconnect replaces the table.
+source.onopen = () => rows.replaceChildren();
+source.onmessage = (message) => {
+  const e = JSON.parse(message.data);
+  if (![...level.options].some((option) => option.value === e.level)) {
+    level.add(new Option(e.level));
+  }
+  const row = document.createElement("tr");
+  row.className = e.level;
+  row.dataset.level = e.level;
+  row.dataset.container = e.container;
+  const cells = [new Date(e.time).toLocaleString(), e.host || "", e.type, e.container, e.action, e.level];
+  cells.forEach((value, i) => {
+    const cell = document.createElement("td");
+    cell.textContent = value;
+    if (i === cells.length - 1) {
+      cell.className = "level";
+    }
+    row.appendChild(cell);
+  });
+  row.hidden = !visible(row);
+  rows.prepend(row);
+  while (rows.children.length > 200) {
+    rows.lastChild.remove();
+  }
+};
+</script>
+</body>
+</html>
+`
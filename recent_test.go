@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"testing"
+	"time"
+)
+
+func TestRecentBufferKeepsNewestEvents(t *testing.T) {
+	b := recentBuffer{subscribers: make(map[chan recentEvent]bool)}
+	add := func(i int) {
+		b.add(notification{
+			event:      events.Message{Type: events.ContainerEventType, Action: "start", Actor: events.Actor{Attributes: map[string]string{"name": fmt.Sprintf("c%d", i)}}},
+			level:      "info",
+			receivedAt: time.Now(),
+		})
+	}
+	for i := 0; i < recentEventLimit+5; i++ {
+		add(i)
+	}
+
+	backlog, live, cancel := b.subscribe()
+	defer cancel()
+	if len(backlog) != recentEventLimit {
+		t.Fatalf("len(backlog) = %d, want %d", len(backlog), recentEventLimit)
+	}
+	if first, last := backlog[0].Container, backlog[len(backlog)-1].Container; first != "c5" || last != fmt.Sprintf("c%d", recentEventLimit+4) {
+		t.Fatalf("backlog runs from %s to %s, want the newest %d events in order", first, last, recentEventLimit)
+	}
+
+	add(999)
+	select {
+	case e := <-live:
+		if e.Container != "c999" {
+			t.Fatalf("subscriber received %s, want c999", e.Container)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not receive the new event")
+	}
+}
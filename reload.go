@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// configPollInterval is how often the config file is checked for changes.
+const configPollInterval = 5 * time.Second
+
+// watchConfig requests a reload whenever the latest modification time of the config file or of
+// the other watched paths changes. Pending requests are coalesced, so a burst of writes triggers
+// a single reload.
+func watchConfig(reloads chan<- struct{}, paths ...string) {
+	lastMod := latestModTime(paths)
+	for range time.Tick(configPollInterval) {
+		modTime := latestModTime(paths)
+		if modTime.IsZero() || modTime.Equal(lastMod) {
+			continue
+		}
+		lastMod = modTime
+
+		select {
+		case reloads <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// latestModTime returns the latest modification time of the paths, and for directories also of
+// the files in them, so both replacing a file and editing it in place are noticed. Empty and
+// missing paths are skipped.
+func latestModTime(paths []string) time.Time {
+	var latest time.Time
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		if !info.IsDir() {
+			continue
+		}
+		entries, _ := os.ReadDir(p)
+		for _, entry := range entries {
+			if info, err := os.Stat(filepath.Join(p, entry.Name())); err == nil && info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+		}
+	}
+	return latest
+}
+
+// reloadConfig re-reads the config file and rebuilds the action maps. On any error, including
+// invalid JSON or an active policy whose webhooks the new config rejects, the current config is
+// kept.
+func reloadConfig(current *Config) *Config {
+	cfg, err := loadConfig(configFile)
+	if err == nil {
+		err = validateConfig(cfg)
+	}
+	if err == nil {
+		err = validateWebhooks(cfg)
+	}
+	if p := activePolicy.Load(); err == nil && p != nil {
+		err = validatePolicy(p, cfg)
+	}
+	if err != nil {
+		slog.Error("Failed to reload config, keeping the previous one", "error", err)
+		return current
+	}
+	activeConfig.Store(cfg)
+	populateActionMaps(cfg)
+	setupLogging(cfg)
+	outboundLimit.configure(cfg)
+	slog.Info("Config reloaded")
+	return cfg
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"strings"
+)
+
+// shortIDLength is the length of the container ID prefix shown by the Docker CLI.
+const shortIDLength = 12
+
+// containerFields returns the image, exit code and short ID of the container, skipping values the
+// event does not carry.
+func containerFields(event events.Message) []embedField {
+	var fields []embedField
+	if image := event.Actor.Attributes["image"]; image != "" {
+		fields = append(fields, embedField{Name: "Image", Value: image, Inline: true})
+	}
+	if exitCode := event.Actor.Attributes["exitCode"]; exitCode != "" {
+		fields = append(fields, embedField{Name: "Exit Code", Value: exitCode, Inline: true})
+	}
+	if id := event.Actor.ID; id != "" {
+		fields = append(fields, embedField{Name: "ID", Value: id[:min(len(id), shortIDLength)], Inline: true})
+	}
+	return fields
+}
+
+// uniqueFields drops fields whose name already appeared, such as an exit code reported by both
+// the event and the enrichment.
+func uniqueFields(fields []embedField) []embedField {
+	seen := make(map[string]bool)
+	unique := fields[:0]
+	for _, f := range fields {
+		if seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		unique = append(unique, f)
+	}
+	return unique
+}
+
+// containerFieldAttributes are the attributes containerFields already shows for container events.
+var containerFieldAttributes = map[string]bool{"image": true, "exitCode": true}
+
+// attributeFields returns the configured event attributes that are present on the event, skipping
+// those containerFields already shows.
+func attributeFields(event events.Message, cfg *Config) []embedField {
+	var fields []embedField
+	for _, key := range cfg.EmbedAttributes {
+		if event.Type == events.ContainerEventType && containerFieldAttributes[key] {
+			continue
+		}
+		if value, ok := event.Actor.Attributes[key]; ok && value != "" {
+			fields = append(fields, embedField{Name: key, Value: value, Inline: true})
+		}
+	}
+	return fields
+}
+
+// renderFields lays out name/value pairs according to AttributeRenderMode. It returns text to append
+// to the description and the fields to attach to the embed; only one of them is non-empty.
+func renderFields(fields []embedField, cfg *Config) (string, []embedField) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	switch cfg.AttributeRenderMode {
+	case "table":
+		width := 0
+		for _, f := range fields {
+			width = max(width, len(f.Name))
+		}
+		var sb strings.Builder
+		sb.WriteString("\n```\n")
+		for _, f := range fields {
+			fmt.Fprintf(&sb, "%-*s  %s\n", width, f.Name, strings.ReplaceAll(f.Value, "\n", " "))
+		}
+		sb.WriteString("```")
+		return sb.String(), nil
+	case "inline":
+		var sb strings.Builder
+		for _, f := range fields {
+			fmt.Fprintf(&sb, "\n**%s**: `%s`", f.Name, f.Value)
+		}
+		return sb.String(), nil
+	default:
+		return "", fields
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+	"testing"
+)
+
+func TestEmbedAttributesDoNotRepeatContainerFields(t *testing.T) {
+	event := events.Message{
+		Type: events.ContainerEventType,
+		Actor: events.Actor{
+			ID:         "0123456789abcdef",
+			Attributes: map[string]string{"image": "nginx:1.27", "exitCode": "137", "com.example.team": "web"},
+		},
+	}
+	cfg := &Config{EmbedAttributes: []string{"image", "exitCode", "com.example.team"}}
+
+	fields := uniqueFields(append(containerFields(event), attributeFields(event, cfg)...))
+	want := []string{"Image", "Exit Code", "ID", "com.example.team"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields = %v, want %v", fields, want)
+	}
+	for i, f := range fields {
+		if f.Name != want[i] {
+			t.Fatalf("field %d = %q, want %q", i, f.Name, want[i])
+		}
+	}
+}
+
+func TestEmbedAttributesOfOtherEventTypes(t *testing.T) {
+	event := events.Message{Type: events.ImageEventType, Actor: events.Actor{Attributes: map[string]string{"image": "nginx:1.27"}}}
+	fields := attributeFields(event, &Config{EmbedAttributes: []string{"image"}})
+	if len(fields) != 1 || fields[0].Value != "nginx:1.27" {
+		t.Fatalf("attributeFields() = %v, want the image of a non-container event", fields)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+)
+
+// runID identifies this DockaCord process in notifications so alerts from one run can be correlated.
+var runID = newRunID()
+
+// newRunID returns DOCKACORD_RUN_ID if set, otherwise a random 16-character hex ID.
+func newRunID() string {
+	if id := os.Getenv("DOCKACORD_RUN_ID"); id != "" {
+		return id
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
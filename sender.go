@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+)
+
+// defaultSendQueue bounds the notifications waiting for the sender when rate_limit_queue is unset.
+const defaultSendQueue = 1000
+
+// sendQueue hands notifications to a worker goroutine, so slow webhooks, retries and the outbound
+// rate limit never hold up the event loop and its signal handling. While the limiter is saturated
+// notifications wait here, up to the queue size. Queued sends count as in flight, so shutdown
+// waits for them.
+type sendQueue struct {
+	jobs chan func()
+}
+
+var sender = newSendQueue(defaultSendQueue)
+
+// newSendQueue creates a send queue holding up to size notifications.
+func newSendQueue(size int) *sendQueue {
+	return &sendQueue{jobs: make(chan func(), size)}
+}
+
+// sendQueueSize returns the configured send queue size.
+func sendQueueSize(cfg *Config) int {
+	if cfg.RateLimitQueue <= 0 {
+		return defaultSendQueue
+	}
+	return cfg.RateLimitQueue
+}
+
+// submit queues the send, dropping it when the queue is full. It reports whether it was queued.
+func (q *sendQueue) submit(job func()) bool {
+	inFlight.start()
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		inFlight.done()
+		notificationsSuppressed.inc("send_queue_full")
+		slog.Error("Send queue full, dropping notification", "capacity", cap(q.jobs))
+		return false
+	}
+}
+
+// run performs queued sends in order until ctx is cancelled.
+func (q *sendQueue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.jobs:
+			job()
+			inFlight.done()
+		}
+	}
+}
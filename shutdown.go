@@ -0,0 +1,83 @@
+package main
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// defaultShutdownTimeout is used when shutdown_timeout_seconds is unset.
+const defaultShutdownTimeout = 10 * time.Second
+
+// drainPollInterval is how often shutdown checks whether in-flight deliveries finished.
+const drainPollInterval = 50 * time.Millisecond
+
+// deliveryTracker counts notifications and webhook deliveries that are still running. A plain
+// counter is used instead of a sync.WaitGroup because timers may start deliveries while shutdown
+// is already waiting.
+type deliveryTracker struct {
+	n atomic.Int64
+}
+
+var inFlight deliveryTracker
+
+// start registers a running delivery.
+func (t *deliveryTracker) start() {
+	t.n.Add(1)
+}
+
+// done marks a delivery registered with start as finished.
+func (t *deliveryTracker) done() {
+	t.n.Add(-1)
+}
+
+// wait blocks until no delivery is running, the timeout expires or a signal arrives. It reports
+// whether every delivery finished.
+func (t *deliveryTracker) wait(timeout time.Duration, signals <-chan os.Signal) bool {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for t.n.Load() > 0 {
+		select {
+		case <-deadline:
+			return false
+		case sig := <-signals:
+			log.Printf("Received signal %v while draining, exiting immediately", sig)
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// shutdownTimeout returns how long shutdown waits for in-flight deliveries.
+func shutdownTimeout(cfg *Config) time.Duration {
+	if cfg.ShutdownTimeoutSeconds <= 0 {
+		return defaultShutdownTimeout
+	}
+	return time.Duration(cfg.ShutdownTimeoutSeconds) * time.Second
+}
+
+// flushHeld sends everything still held back for a window: throttled notifications, image groups,
+// dedup and burst summaries and, last, the batched embeds the others may have added to.
+func flushHeld(cfg *Config) {
+	throttle.releaseAll(cfg)
+	imageGroups.flushAll(cfg)
+	dedup.closeAll(cfg)
+	burst.flush(cfg)
+	batch.flush()
+}
+
+// drain sends any held notifications and waits for queued and in-flight deliveries before the app
+// context is cancelled, which aborts the remaining retries. The flush runs on the send queue
+// behind the notifications already queued and counts against the timeout like any other delivery.
+func drain(cfg *Config, signals <-chan os.Signal) {
+	sender.submit(func() { flushHeld(currentConfig(cfg)) })
+	timeout := shutdownTimeout(cfg)
+	if !inFlight.wait(timeout, signals) {
+		slog.Warn("Abandoning in-flight notifications", "count", inFlight.n.Load(), "timeout", timeout)
+	}
+	stopApp()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"github.com/docker/docker/api/types/events"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// resetAppContext replaces the app context cancelled by drain, so later tests can still deliver.
+// The send queue and delivery count are replaced too, so jobs a test abandoned never run with
+// another test's state.
+func resetAppContext() {
+	appCtx, stopApp = context.WithCancel(context.Background())
+	sender = newSendQueue(defaultSendQueue)
+	inFlight.n.Store(0)
+}
+
+func TestDrainWaitsForStartedNotification(t *testing.T) {
+	t.Cleanup(resetAppContext)
+	var delivered atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		delivered.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Webhook: srv.URL, ShutdownTimeoutSeconds: 5}
+	n := notification{
+		event:      events.Message{Type: events.ContainerEventType, Action: "start", Actor: events.Actor{Attributes: map[string]string{"name": "web"}}},
+		level:      "info",
+		receivedAt: time.Now(),
+	}
+	go sender.run(appCtx)
+	if !sender.submit(func() { notify(n, cfg) }) {
+		t.Fatal("submit() dropped the notification")
+	}
+
+	drain(cfg, nil)
+	if !delivered.Load() {
+		t.Fatal("drain() returned before the notification was delivered")
+	}
+	if n := inFlight.n.Load(); n != 0 {
+		t.Fatalf("inFlight = %d after drain, want 0", n)
+	}
+}
+
+func TestDrainGivesUpAfterTimeout(t *testing.T) {
+	t.Cleanup(resetAppContext)
+	release := make(chan struct{})
+	cfg := &Config{ShutdownTimeoutSeconds: 1}
+	go sender.run(appCtx)
+	sender.submit(func() { <-release })
+
+	start := time.Now()
+	drain(cfg, nil)
+	elapsed := time.Since(start)
+	close(release)
+	// Let the stuck job finish before the delivery count is reset for the next test.
+	for inFlight.n.Load() > 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if elapsed > 3*time.Second {
+		t.Fatalf("drain() took %s, want it bounded by the 1s shutdown timeout", elapsed)
+	}
+}
+
+func TestDrainReleasesThrottledNotification(t *testing.T) {
+	t.Cleanup(resetAppContext)
+	var delivered atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Webhook: srv.URL, ShutdownTimeoutSeconds: 5, ThrottleSeconds: 60}
+	n := notification{
+		event:      events.Message{Type: events.ContainerEventType, Action: "die", Actor: events.Actor{ID: "abc", Attributes: map[string]string{"name": "web"}}},
+		level:      "error",
+		receivedAt: time.Now(),
+	}
+	throttle.hold(n, cfg)
+	go sender.run(appCtx)
+
+	drain(cfg, nil)
+	if got := delivered.Load(); got != 1 {
+		t.Fatalf("deliveries = %d, want the throttled notification sent on drain", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Signal actions that can be configured in Config.Signals.
+const (
+	signalShutdown = "shutdown"
+	signalExit     = "exit"
+	signalReload   = "reload"
+	signalIgnore   = "ignore"
+)
+
+// signalsByName lists the signals whose handling can be configured.
+var signalsByName = map[string]syscall.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// defaultSignalActions apply to signals that are not configured.
+var defaultSignalActions = map[string]string{
+	"SIGHUP":  signalReload,
+	"SIGINT":  signalShutdown,
+	"SIGTERM": signalShutdown,
+}
+
+// signalAction returns the configured action for the signal.
+func signalAction(sig os.Signal, cfg *Config) string {
+	for name, s := range signalsByName {
+		if s != sig {
+			continue
+		}
+		if action, ok := cfg.Signals[name]; ok {
+			return action
+		}
+		if action, ok := defaultSignalActions[name]; ok {
+			return action
+		}
+	}
+	return signalShutdown
+}
+
+// handledSignals returns the signals to subscribe to: the defaults plus every configured one.
+// Signals added by a reload only take effect after a restart.
+func handledSignals(cfg *Config) []os.Signal {
+	var sigs []os.Signal
+	for name, s := range signalsByName {
+		_, configured := cfg.Signals[name]
+		_, handled := defaultSignalActions[name]
+		if configured || handled {
+			sigs = append(sigs, s)
+		}
+	}
+	return sigs
+}
+
+// validateSignals rejects unknown signal names and actions.
+func validateSignals(cfg *Config) error {
+	for name, action := range cfg.Signals {
+		if _, ok := signalsByName[name]; !ok {
+			return fmt.Errorf("unsupported signal %q in signals", name)
+		}
+		switch action {
+		case signalShutdown, signalExit, signalReload, signalIgnore:
+		default:
+			return fmt.Errorf("invalid action %q for %s: must be shutdown, exit, reload or ignore", action, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+)
+
+// sendStartupMessage announces that DockaCord started, so a broken webhook shows up right away
+// instead of on the next event.
+func sendStartupMessage(cfg *Config) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	actions := levelActions(cfg)
+	levels := make([]string, 0, len(actions))
+	for level, list := range actions {
+		if len(list) > 0 {
+			levels = append(levels, fmt.Sprintf("%s (%d)", level, len(list)))
+		}
+	}
+	sort.Strings(levels)
+
+	description := fmt.Sprintf("**Host**: `%s`\n**Docker hosts**: %d\n**Event types**: %s\n**Levels**: %s\n**Run**: `%s`",
+		hostname, len(dockerHosts(cfg)), strings.Join(eventTypes(cfg), ", "), strings.Join(levels, ", "), runID)
+	embed := newEmbed("DockaCord Started - INFO", description, "info", cfg)
+	if err := sendEmbeds(cfg, webhookFor("info", cfg), embed); err != nil {
+		slog.Error("Failed to send startup message", "error", err)
+		return
+	}
+	log.Println("Successfully sent startup message")
+}
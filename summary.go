@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Discord limits that summaries are paginated to stay within.
+const (
+	summaryDescriptionLimit = 4000
+	messageCharLimit        = 5500
+	messageEmbedLimit       = 10
+)
+
+// summaryPages splits a summary into embeds whose descriptions fit Discord's limits. The header
+// starts the first page and lines are never split. With more than one page, titles carry a
+// "(1/3)" style page indicator.
+func summaryPages(title string, header string, lines []string, level string, cfg *Config) []map[string]interface{} {
+	var pages []string
+	current := header
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > summaryDescriptionLimit {
+			line = string([]rune(line)[:summaryDescriptionLimit-1]) + "…"
+		}
+		if current != "" && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(line) > summaryDescriptionLimit {
+			pages = append(pages, current)
+			current = line
+			continue
+		}
+		if current != "" {
+			current += "\n"
+		}
+		current += line
+	}
+	pages = append(pages, current)
+
+	embeds := make([]map[string]interface{}, 0, len(pages))
+	for i, page := range pages {
+		pageTitle := title
+		if len(pages) > 1 {
+			pageTitle = fmt.Sprintf("%s (%d/%d)", title, i+1, len(pages))
+		}
+		embeds = append(embeds, newEmbed(pageTitle, page, level, cfg))
+	}
+	return embeds
+}
+
+// sendPaged sends the embeds in as few messages as Discord's per-message limits allow.
+func sendPaged(cfg *Config, webhookURL string, embeds []map[string]interface{}) error {
+	var batch []map[string]interface{}
+	size := 0
+	for _, embed := range embeds {
+		embedSize := embedChars(embed)
+		if len(batch) > 0 && (len(batch) == messageEmbedLimit || size+embedSize > messageCharLimit) {
+			if err := sendEmbeds(cfg, webhookURL, batch...); err != nil {
+				return err
+			}
+			batch, size = nil, 0
+		}
+		batch = append(batch, embed)
+		size += embedSize
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return sendEmbeds(cfg, webhookURL, batch...)
+}
+
+// embedChars counts the characters of an embed that Discord adds up against the message limit.
+func embedChars(embed map[string]interface{}) int {
+	n := 0
+	for _, key := range []string{"title", "description"} {
+		if s, ok := embed[key].(string); ok {
+			n += utf8.RuneCountInString(s)
+		}
+	}
+	for _, key := range []string{"footer", "author"} {
+		if m, ok := embed[key].(map[string]string); ok {
+			n += utf8.RuneCountInString(m["text"]) + utf8.RuneCountInString(m["name"])
+		}
+	}
+	if fields, ok := embed["fields"].([]embedField); ok {
+		for _, f := range fields {
+			n += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+		}
+	}
+	return n
+}
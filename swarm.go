@@ -0,0 +1,43 @@
+package main
+
+import (
+	"github.com/docker/docker/api/types/events"
+)
+
+// Labels Docker sets on containers that run as Swarm tasks.
+const (
+	swarmServiceLabel = "com.docker.swarm.service.name"
+	swarmNodeLabel    = "com.docker.swarm.node.id"
+)
+
+// swarmInfo returns the Swarm service name and node ID of the event, if it has them.
+// Service events carry the service name directly; task containers carry it as a label.
+func swarmInfo(event events.Message) (service string, node string) {
+	if event.Type == events.ServiceEventType {
+		return event.Actor.Attributes["name"], event.Actor.Attributes[swarmNodeLabel]
+	}
+	return event.Actor.Attributes[swarmServiceLabel], event.Actor.Attributes[swarmNodeLabel]
+}
+
+// swarmFields describes the Swarm service and node of the event when it is part of a service.
+func swarmFields(event events.Message) []embedField {
+	service, node := swarmInfo(event)
+	var fields []embedField
+	if service != "" {
+		fields = append(fields, embedField{Name: "Service", Value: service, Inline: true})
+	}
+	if node != "" {
+		fields = append(fields, embedField{Name: "Node", Value: node, Inline: true})
+	}
+	return fields
+}
+
+// serviceAllowed reports whether the event passes the IncludeServices patterns.
+// Events outside of a Swarm service never match.
+func serviceAllowed(event events.Message, cfg *Config) bool {
+	if len(cfg.IncludeServices) == 0 {
+		return true
+	}
+	service, _ := swarmInfo(event)
+	return service != "" && matchesAny(service, cfg.IncludeServices)
+}
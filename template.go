@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// MessageTemplate holds text/template sources for the title and description of a level's
+// notifications. Empty sources keep the default format.
+type MessageTemplate struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// compiledTemplate is a MessageTemplate parsed at startup.
+type compiledTemplate struct {
+	title       *template.Template
+	description *template.Template
+}
+
+// templateData is passed to message templates.
+type templateData struct {
+	ContainerName string
+	Host          string
+	Type          string
+	Action        string
+	Level         string
+	Time          time.Time
+	Image         string
+	ID            string
+	Attributes    map[string]string
+}
+
+// compileTemplates parses the templates of every level into cfg, so a bad template fails at
+// startup or reload instead of on every event.
+func compileTemplates(cfg *Config) error {
+	cfg.compiledTemplates = make(map[string]compiledTemplate)
+	for level, t := range cfg.Templates {
+		var compiled compiledTemplate
+		var err error
+		if compiled.title, err = parseTemplate(level+" title", t.Title); err != nil {
+			return err
+		}
+		if compiled.description, err = parseTemplate(level+" description", t.Description); err != nil {
+			return err
+		}
+		cfg.compiledTemplates[level] = compiled
+	}
+	var err error
+	cfg.dedupKey, err = parseTemplate("dedup key", cfg.DedupKeyTemplate)
+	return err
+}
+
+// parseTemplate parses a template source, returning nil for an empty one.
+func parseTemplate(name string, source string) (*template.Template, error) {
+	if source == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %v", name, err)
+	}
+	return t, nil
+}
+
+// renderTemplate executes t with the notification data, returning fallback when t is nil or fails.
+func renderTemplate(t *template.Template, n notification, at time.Time, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, newTemplateData(n, at)); err != nil {
+		slog.Warn("Failed to render template, using the default", "template", t.Name(), "error", err)
+		return fallback
+	}
+	return sb.String()
+}
+
+// newTemplateData returns the fields of the notification that templates can use.
+func newTemplateData(n notification, at time.Time) templateData {
+	attrs := n.event.Actor.Attributes
+	return templateData{
+		ContainerName: attrs["name"],
+		Host:          n.host,
+		Type:          string(n.event.Type),
+		Action:        string(n.event.Action),
+		Level:         n.level,
+		Time:          at,
+		Image:         attrs["image"],
+		ID:            n.event.Actor.ID,
+		Attributes:    attrs,
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// latestThrottle holds notifications per container and sends only the most recent one per window.
+type latestThrottle struct {
+	mu      sync.Mutex
+	pending map[string]notification
+}
+
+var throttle = latestThrottle{pending: make(map[string]notification)}
+
+// throttled reports whether the action is subject to the latest-wins throttle.
+func throttled(action string, cfg *Config) bool {
+	if cfg.ThrottleSeconds <= 0 {
+		return false
+	}
+	if len(cfg.ThrottleActions) == 0 {
+		return true
+	}
+	for _, a := range cfg.ThrottleActions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// hold replaces any pending notification for the container and starts its window if none is open.
+func (t *latestThrottle) hold(n notification, cfg *Config) {
+	key := n.event.Actor.ID
+
+	t.mu.Lock()
+	previous, open := t.pending[key]
+	t.pending[key] = n
+	t.mu.Unlock()
+
+	if open {
+		notificationsSuppressed.inc("throttled")
+		slog.Info("Throttle replaced pending notification", "previous_action", previous.event.Action, "action", n.event.Action)
+		return
+	}
+	time.AfterFunc(time.Duration(cfg.ThrottleSeconds)*time.Second, func() {
+		sender.submit(func() { t.release(key, currentConfig(cfg)) })
+	})
+}
+
+// release sends the latest notification held for the container once its window closes.
+func (t *latestThrottle) release(key string, cfg *Config) {
+	t.mu.Lock()
+	n, ok := t.pending[key]
+	delete(t.pending, key)
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	notify(n, cfg)
+}
+
+// releaseAll sends every held notification without waiting for its window, used on shutdown.
+func (t *latestThrottle) releaseAll(cfg *Config) {
+	t.mu.Lock()
+	keys := make([]string, 0, len(t.pending))
+	for key := range t.pending {
+		keys = append(keys, key)
+	}
+	t.mu.Unlock()
+
+	for _, key := range keys {
+		t.release(key, cfg)
+	}
+}
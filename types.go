@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"github.com/docker/docker/api/types/events"
+	"strings"
+)
+
+// supportedTypes lists the Docker event types that can be subscribed to.
+var supportedTypes = map[events.Type]bool{
+	events.ContainerEventType: true,
+	events.ImageEventType:     true,
+	events.VolumeEventType:    true,
+	events.NetworkEventType:   true,
+	events.PluginEventType:    true,
+	events.DaemonEventType:    true,
+	events.ServiceEventType:   true,
+	events.NodeEventType:      true,
+	events.SecretEventType:    true,
+	events.ConfigEventType:    true,
+}
+
+// eventTypes returns the configured event types, defaulting to containers only.
+func eventTypes(cfg *Config) []string {
+	if len(cfg.Types) == 0 {
+		return []string{string(events.ContainerEventType)}
+	}
+	return cfg.Types
+}
+
+// containersOnly reports whether only container events are subscribed.
+func containersOnly(cfg *Config) bool {
+	types := eventTypes(cfg)
+	return len(types) == 1 && types[0] == string(events.ContainerEventType)
+}
+
+// validateTypes rejects unknown event types, also as keys of type_webhooks.
+func validateTypes(cfg *Config) error {
+	for _, t := range cfg.Types {
+		if !supportedTypes[events.Type(t)] {
+			return fmt.Errorf("unsupported event type %q in types", t)
+		}
+	}
+	for t := range cfg.TypeWebhooks {
+		if !supportedTypes[events.Type(t)] {
+			return fmt.Errorf("unsupported event type %q in type_webhooks", t)
+		}
+	}
+	return nil
+}
+
+// actionKey returns the key an event is classified by. Container actions are used as is, other
+// types are prefixed so that e.g. "volume:create" does not collide with the container's "create".
+func actionKey(event events.Message) string {
+	if event.Type == events.ContainerEventType {
+		return string(event.Action)
+	}
+	return string(event.Type) + ":" + string(event.Action)
+}
+
+// resourceLabel returns the display name of the event type, e.g. "Volume".
+func resourceLabel(event events.Message) string {
+	t := string(event.Type)
+	if t == "" {
+		return "Container"
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// resourceName returns the name of the resource the event concerns, falling back to its ID.
+func resourceName(event events.Message) string {
+	if name := event.Actor.Attributes["name"]; name != "" {
+		return name
+	}
+	return event.Actor.ID
+}